@@ -16,10 +16,13 @@ func SetDefaults() {
 	viper.SetDefault("base-local-dir", filepath.Clean(filepath.Join(filepath.Dir(file), "../", "testdata")))
 	viper.SetDefault("profiles-file", "profiles.json")
 	viper.SetDefault("installations-file", "installations.json")
+	viper.SetDefault("credentials-file", "credentials.json")
+	viper.SetDefault("platforms-file", "platforms.json")
 	viper.SetDefault("dry-run", false)
 	viper.SetDefault("api-base", "https://api.ficsit.dev")
 	viper.SetDefault("graphql-api", "/v2/query")
 	viper.SetDefault("concurrent-downloads", 5)
+	viper.SetDefault("save-backup-retention", 10)
 
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
@@ -18,6 +18,7 @@ type GlobalContext struct {
 	Profiles      *Profiles
 	APIClient     graphql.Client
 	Provider      provider.Provider
+	Platforms     []Platform
 }
 
 var globalContext *GlobalContext
@@ -51,6 +52,11 @@ func InitCLI(apiOnly bool) (*GlobalContext, error) {
 			return nil, fmt.Errorf("failed to load cache: %w", err)
 		}
 
+		platforms, err := LoadPlatforms()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load platforms: %w", err)
+		}
+
 		err = localregistry.Init()
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize local registry: %w", err)
@@ -61,6 +67,7 @@ func InitCLI(apiOnly bool) (*GlobalContext, error) {
 			Profiles:      profiles,
 			APIClient:     apiClient,
 			Provider:      mixedProvider,
+			Platforms:     platforms,
 		}
 	} else {
 		globalContext = &GlobalContext{
@@ -0,0 +1,260 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/argon2"
+)
+
+// PassphraseEnv is the environment variable used to unlock the credential store
+const PassphraseEnv = "FICSIT_CREDENTIALS_PASSPHRASE"
+
+const storeVersion = 1
+
+var (
+	// ErrLocked is returned when the credential store has no passphrase to unlock it with
+	ErrLocked = errors.New("credential store is locked, set " + PassphraseEnv + " to unlock it")
+
+	// ErrInvalidPassphrase is returned when the credential store can not be decrypted with the passphrase
+	ErrInvalidPassphrase = errors.New("invalid credential store passphrase")
+)
+
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Store holds credentials in memory and persists them encrypted with a passphrase
+type Store struct {
+	credentials map[string]Credential
+	path        string
+	passphrase  []byte
+}
+
+type storeFile struct {
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+	Version int    `json:"version"`
+}
+
+// Open decrypts the store at the provided path, or returns an empty store if it does not exist
+func Open(path string, passphrase string) (*Store, error) {
+	if passphrase == "" {
+		return nil, ErrLocked
+	}
+
+	store := &Store{
+		path:        path,
+		passphrase:  []byte(passphrase),
+		credentials: make(map[string]Credential),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+
+		return nil, fmt.Errorf("failed to read credential store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse credential store: %w", err)
+	}
+
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported credential store version: %d", file.Version)
+	}
+
+	gcm, err := newCipher(store.passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	if err := json.Unmarshal(plain, &store.credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+
+	return store, nil
+}
+
+// Get returns the credential stored under the provided id
+func (s *Store) Get(id string) (Credential, bool) {
+	credential, ok := s.credentials[id]
+	return credential, ok
+}
+
+// Set stores the credential under the provided id, replacing any existing one
+func (s *Store) Set(id string, credential Credential) {
+	s.credentials[id] = credential
+}
+
+// Delete removes the credential with the provided id, returning false if it did not exist
+func (s *Store) Delete(id string) bool {
+	if _, ok := s.credentials[id]; !ok {
+		return false
+	}
+
+	delete(s.credentials, id)
+	return true
+}
+
+// IDs returns the sorted ids of all stored credentials
+func (s *Store) IDs() []string {
+	ids := make([]string, 0, len(s.credentials))
+	for id := range s.credentials {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Save encrypts the store with a fresh salt and nonce and writes it to disk
+func (s *Store) Save() error {
+	if viper.GetBool("dry-run") {
+		slog.Info("dry-run: skipping credential store saving")
+		return nil
+	}
+
+	plain, err := json.Marshal(s.credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newCipher(s.passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(storeFile{
+		Version: storeVersion,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create credential store directory: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write credential store: %w", err)
+	}
+
+	return nil
+}
+
+func newCipher(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(passphrase, salt, 1, 64*1024, 4, 32)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return gcm, nil
+}
+
+var (
+	defaultStore     *Store
+	defaultStoreMux  sync.Mutex
+	passphrasePrompt func() (string, error)
+)
+
+// SetPassphrasePrompt sets the function used to ask for the passphrase
+// when it is not provided through the environment or config
+func SetPassphrasePrompt(prompt func() (string, error)) {
+	passphrasePrompt = prompt
+}
+
+// Unlock opens the credential store under local-dir with the provided passphrase,
+// so later calls to Default do not need to ask for it
+func Unlock(passphrase string) error {
+	defaultStoreMux.Lock()
+	defer defaultStoreMux.Unlock()
+
+	store, err := Open(Path(), passphrase)
+	if err != nil {
+		return err
+	}
+
+	defaultStore = store
+
+	return nil
+}
+
+// Path returns the location of the credential store
+func Path() string {
+	return filepath.Join(viper.GetString("local-dir"), viper.GetString("credentials-file"))
+}
+
+// Default opens the credential store under local-dir, unlocking it once per process
+func Default() (*Store, error) {
+	defaultStoreMux.Lock()
+	defer defaultStoreMux.Unlock()
+
+	path := Path()
+	if defaultStore != nil && defaultStore.path == path {
+		return defaultStore, nil
+	}
+
+	passphrase := viper.GetString("credentials-passphrase")
+	if passphrase == "" {
+		passphrase = os.Getenv(PassphraseEnv)
+	}
+
+	if passphrase == "" && passphrasePrompt != nil {
+		var err error
+		passphrase, err = passphrasePrompt()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credential store passphrase: %w", err)
+		}
+	}
+
+	store, err := Open(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultStore = store
+
+	return store, nil
+}
@@ -0,0 +1,72 @@
+package credentials
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// QueryParam is the url query parameter referencing a stored credential
+const QueryParam = "credential"
+
+// Resolve returns a copy of the url with the referenced credential filled in as user info.
+// Urls without a credential reference are returned unchanged.
+func Resolve(u *url.URL) (*url.URL, error) {
+	query := u.Query()
+	id := query.Get(QueryParam)
+	if id == "" {
+		return u, nil
+	}
+
+	store, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	credential, ok := store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("credential not found: %s", id)
+	}
+
+	resolved := *u
+
+	username := credential.Username
+	if username == "" {
+		username = u.User.Username()
+	}
+
+	resolved.User = url.UserPassword(username, credential.Password)
+
+	query.Del(QueryParam)
+	resolved.RawQuery = query.Encode()
+
+	return &resolved, nil
+}
+
+// Extract moves a password embedded in the url into a credential.
+// It returns the url referencing the credential id instead, or ok false if the url has no password.
+func Extract(u *url.URL) (*url.URL, string, Credential, bool) {
+	password, ok := u.User.Password()
+	if !ok {
+		return u, "", Credential{}, false
+	}
+
+	id := u.Host
+	if u.User.Username() != "" {
+		id = u.User.Username() + "@" + u.Host
+	}
+
+	extracted := *u
+	extracted.User = nil
+	if u.User.Username() != "" {
+		extracted.User = url.User(u.User.Username())
+	}
+
+	query := u.Query()
+	query.Set(QueryParam, id)
+	extracted.RawQuery = query.Encode()
+
+	return &extracted, id, Credential{
+		Username: u.User.Username(),
+		Password: password,
+	}, true
+}
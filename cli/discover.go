@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+const (
+	steamClientAppID = "526870"
+	steamServerAppID = "1690800"
+)
+
+// steamRoots are the directories, relative to the home directory, that Steam and SteamCMD install themselves into
+var steamRoots = []string{
+	filepath.Join(".steam", "steam"),
+	filepath.Join(".steam", "root"),
+	filepath.Join(".local", "share", "Steam"),
+	filepath.Join(".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	filepath.Join(".var", "app", "com.valvesoftware.Steam", "data", "Steam"),
+	filepath.Join("snap", "steam", "common", ".local", "share", "Steam"),
+	"Steam",
+	filepath.Join(".steam", "steamcmd"),
+}
+
+// serverDirectories are the directories, relative to the home directory, that guides commonly install dedicated servers into
+var serverDirectories = []string{
+	"SatisfactoryDedicatedServer",
+	"serverfiles",
+}
+
+// legendaryConfigs are the installed game lists, relative to the home directory, of Heroic and legendary
+var legendaryConfigs = map[string]string{
+	filepath.Join(".config", "heroic", "legendaryConfig", "legendary", "installed.json"):                                              "Heroic",
+	filepath.Join(".var", "app", "com.heroicgameslauncher.hgl", "config", "heroic", "legendaryConfig", "legendary", "installed.json"): "Heroic",
+	filepath.Join(".config", "legendary", "installed.json"):                                                                           "Legendary",
+}
+
+// winePrefixes are the wine prefixes, relative to the home directory, that the Epic Games launcher is commonly installed into
+var winePrefixes = []string{
+	".wine",
+	filepath.Join("Games", "epic-games-store"),
+}
+
+// DiscoveredInstallation is a game installation found on the local machine
+type DiscoveredInstallation struct {
+	Path   string
+	Source string
+	// Added is true if the installation is already known
+	Added bool
+}
+
+// DiscoverInstallations searches the local machine for game installations
+// made by Steam, SteamCMD, Heroic and Epic under Proton.
+//
+// Only installations that pass validation for the selected profile are returned.
+func (i *Installations) DiscoverInstallations(ctx *GlobalContext) ([]DiscoveredInstallation, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	return i.discoverInstallations(ctx, home), nil
+}
+
+func (i *Installations) discoverInstallations(ctx *GlobalContext, home string) []DiscoveredInstallation {
+	candidates := make([]DiscoveredInstallation, 0)
+
+	libraries := make([]string, 0)
+	for _, root := range steamRoots {
+		libraries = append(libraries, steamLibraries(filepath.Join(home, root))...)
+	}
+
+	libraries = uniquePaths(libraries)
+
+	for _, library := range libraries {
+		for path, appID := range steamApps(library) {
+			source := "Steam"
+			if appID == steamServerAppID {
+				source = "Steam (dedicated server)"
+			}
+			candidates = append(candidates, DiscoveredInstallation{Path: path, Source: source})
+		}
+
+		prefixes, _ := filepath.Glob(filepath.Join(library, "steamapps", "compatdata", "*", "pfx"))
+		for _, prefix := range prefixes {
+			for _, path := range epicGames(prefix) {
+				candidates = append(candidates, DiscoveredInstallation{Path: path, Source: "Epic (Proton)"})
+			}
+		}
+	}
+
+	for _, directory := range serverDirectories {
+		candidates = append(candidates, DiscoveredInstallation{Path: filepath.Join(home, directory), Source: "SteamCMD"})
+	}
+
+	for config, source := range legendaryConfigs {
+		for _, path := range legendaryGames(filepath.Join(home, config)) {
+			candidates = append(candidates, DiscoveredInstallation{Path: path, Source: source})
+		}
+	}
+
+	for _, prefix := range winePrefixes {
+		for _, path := range epicGames(filepath.Join(home, prefix)) {
+			candidates = append(candidates, DiscoveredInstallation{Path: path, Source: "Epic (Wine)"})
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, install := range i.Installations {
+		if !disk.IsRemote(install.Path) {
+			known[resolvePath(install.Path)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	discovered := make([]DiscoveredInstallation, 0)
+
+	for _, candidate := range candidates {
+		candidate.Path = resolvePath(candidate.Path)
+		if seen[candidate.Path] {
+			continue
+		}
+		seen[candidate.Path] = true
+
+		if _, err := os.Stat(candidate.Path); err != nil {
+			continue
+		}
+
+		installation := &Installation{
+			Path:    candidate.Path,
+			Profile: ctx.Profiles.SelectedProfile,
+		}
+
+		if err := installation.Validate(ctx); err != nil {
+			slog.Debug("skipping discovered directory", slog.String("path", candidate.Path), slog.Any("err", err))
+			continue
+		}
+
+		candidate.Added = known[candidate.Path]
+		discovered = append(discovered, candidate)
+	}
+
+	sort.Slice(discovered, func(a, b int) bool {
+		return discovered[a].Path < discovered[b].Path
+	})
+
+	return discovered
+}
+
+// steamLibraries returns the Steam root and every library folder listed in its libraryfolders.vdf
+func steamLibraries(root string) []string {
+	if _, err := os.Stat(root); err != nil {
+		return nil
+	}
+
+	libraries := []string{root}
+
+	for _, vdfPath := range []string{
+		filepath.Join(root, "steamapps", "libraryfolders.vdf"),
+		filepath.Join(root, "config", "libraryfolders.vdf"),
+	} {
+		data, err := os.ReadFile(vdfPath)
+		if err != nil {
+			continue
+		}
+
+		folders, err := parseVDF(data)
+		if err != nil {
+			slog.Warn("failed to parse steam library folders", slog.String("path", vdfPath), slog.Any("err", err))
+			continue
+		}
+
+		for _, folder := range folders.Get("libraryfolders").Children {
+			// Old versions of the file map indices directly to paths
+			path := folder.Value
+			if folder.Children != nil {
+				path = folder.String("path")
+			}
+
+			if path != "" {
+				libraries = append(libraries, path)
+			}
+		}
+	}
+
+	return libraries
+}
+
+// steamApps returns the install directories of the game and dedicated server in a Steam library, mapped to their app ids
+func steamApps(library string) map[string]string {
+	apps := make(map[string]string)
+
+	manifests, _ := filepath.Glob(filepath.Join(library, "steamapps", "appmanifest_*.acf"))
+	for _, manifestPath := range manifests {
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			continue
+		}
+
+		manifest, err := parseVDF(data)
+		if err != nil {
+			slog.Warn("failed to parse steam app manifest", slog.String("path", manifestPath), slog.Any("err", err))
+			continue
+		}
+
+		appID := manifest.String("AppState", "appid")
+		installDir := manifest.String("AppState", "installdir")
+
+		if (appID != steamClientAppID && appID != steamServerAppID) || installDir == "" {
+			continue
+		}
+
+		apps[filepath.Join(library, "steamapps", "common", installDir)] = appID
+	}
+
+	return apps
+}
+
+type legendaryGame struct {
+	Title       string `json:"title"`
+	InstallPath string `json:"install_path"`
+}
+
+// legendaryGames returns the install paths of the game listed in a legendary installed.json
+func legendaryGames(configPath string) []string {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+
+	var installed map[string]legendaryGame
+	if err := json.Unmarshal(data, &installed); err != nil {
+		slog.Warn("failed to parse installed games", slog.String("path", configPath), slog.Any("err", err))
+		return nil
+	}
+
+	paths := make([]string, 0)
+	for _, game := range installed {
+		if strings.Contains(strings.ToLower(game.Title), "satisfactory") && game.InstallPath != "" {
+			paths = append(paths, game.InstallPath)
+		}
+	}
+
+	return paths
+}
+
+// epicGames returns every game directory of the Epic Games launcher installed in a wine prefix
+func epicGames(prefix string) []string {
+	paths, _ := filepath.Glob(filepath.Join(prefix, "drive_c", "Program Files", "Epic Games", "*"))
+	return paths
+}
+
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		path = resolvePath(path)
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+
+	return unique
+}
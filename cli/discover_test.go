@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	testza.AssertNoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	testza.AssertNoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestParseVDF(t *testing.T) {
+	node, err := parseVDF([]byte(`// comment
+"LibraryFolders"
+{
+	"contentstatsid"		"-1234"
+	"0"
+	{
+		"path"		"C:\\Program Files (x86)\\Steam"
+		"apps"
+		{
+			"526870"		"23456789"
+		}
+	}
+	"1"		"/mnt/games" [$LINUX]
+	unquoted value
+}`))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `C:\Program Files (x86)\Steam`, node.String("libraryfolders", "0", "path"))
+	testza.AssertEqual(t, "23456789", node.String("libraryfolders", "0", "Apps", steamClientAppID))
+	testza.AssertEqual(t, "/mnt/games", node.String("libraryfolders", "1"))
+	testza.AssertEqual(t, "value", node.String("libraryfolders", "unquoted"))
+	testza.AssertNil(t, node.Get("libraryfolders", "2", "path"))
+
+	_, err = parseVDF([]byte(`"AppState" { "appid" "526870"`))
+	testza.AssertNotNil(t, err)
+}
+
+func TestDiscoverInstallations(t *testing.T) {
+	home := t.TempDir()
+	library := t.TempDir()
+
+	steam := filepath.Join(home, ".local", "share", "Steam")
+	writeTestFile(t, filepath.Join(steam, "steamapps", "libraryfolders.vdf"), `"libraryfolders"
+{
+	"0" { "path" "`+steam+`" }
+	"1" { "path" "`+library+`" }
+}`)
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(home, ".steam"), 0o755))
+	testza.AssertNoError(t, os.Symlink(steam, filepath.Join(home, ".steam", "steam")))
+
+	// Game client installed by Steam
+	writeTestFile(t, filepath.Join(steam, "steamapps", "appmanifest_526870.acf"), `"AppState" { "appid" "526870" "installdir" "Satisfactory" }`)
+	writeTestFile(t, filepath.Join(steam, "steamapps", "common", "Satisfactory", "FactoryGameSteam.exe"), "")
+
+	// Dedicated server in a secondary library
+	writeTestFile(t, filepath.Join(library, "steamapps", "appmanifest_1690800.acf"), `"AppState" { "appid" "1690800" "installdir" "SatisfactoryDedicatedServer" }`)
+	writeTestFile(t, filepath.Join(library, "steamapps", "common", "SatisfactoryDedicatedServer", "FactoryServer.sh"), "")
+
+	// Other games are ignored
+	writeTestFile(t, filepath.Join(library, "steamapps", "appmanifest_440.acf"), `"AppState" { "appid" "440" "installdir" "Team Fortress 2" }`)
+	writeTestFile(t, filepath.Join(library, "steamapps", "common", "Team Fortress 2", "FactoryGame.exe"), "")
+
+	// Epic under Proton
+	writeTestFile(t, filepath.Join(library, "steamapps", "compatdata", "3000000000", "pfx", "drive_c", "Program Files", "Epic Games", "SatisfactoryExperimental", "FactoryGameEGS.exe"), "")
+	writeTestFile(t, filepath.Join(library, "steamapps", "compatdata", "3000000000", "pfx", "drive_c", "Program Files", "Epic Games", "Launcher", "EpicGamesLauncher.exe"), "")
+
+	// SteamCMD server
+	writeTestFile(t, filepath.Join(home, "SatisfactoryDedicatedServer", "FactoryServer.sh"), "")
+
+	// Heroic
+	heroic := filepath.Join(home, "Games", "Heroic", "Satisfactory")
+	writeTestFile(t, filepath.Join(heroic, "FactoryGameEGS.exe"), "")
+	writeTestFile(t, filepath.Join(home, ".config", "heroic", "legendaryConfig", "legendary", "installed.json"), `{
+	"CrabEA": {"app_name": "CrabEA", "title": "Satisfactory", "install_path": "`+heroic+`"},
+	"Fortnite": {"app_name": "Fortnite", "title": "Fortnite", "install_path": "/nonexistent"}
+}`)
+
+	profiles := &Profiles{Profiles: map[string]*Profile{}, SelectedProfile: DefaultProfileName}
+	_, err := profiles.AddProfile(DefaultProfileName)
+	testza.AssertNoError(t, err)
+
+	ctx := &GlobalContext{Profiles: profiles}
+
+	installations := &Installations{
+		Installations: []*Installation{{Path: filepath.Join(home, "SatisfactoryDedicatedServer"), Profile: DefaultProfileName}},
+	}
+
+	discovered := installations.discoverInstallations(ctx, home)
+
+	expected := []DiscoveredInstallation{
+		{Path: filepath.Join(steam, "steamapps", "common", "Satisfactory"), Source: "Steam"},
+		{Path: filepath.Join(library, "steamapps", "common", "SatisfactoryDedicatedServer"), Source: "Steam (dedicated server)"},
+		{Path: filepath.Join(library, "steamapps", "compatdata", "3000000000", "pfx", "drive_c", "Program Files", "Epic Games", "SatisfactoryExperimental"), Source: "Epic (Proton)"},
+		{Path: filepath.Join(home, "SatisfactoryDedicatedServer"), Source: "SteamCMD", Added: true},
+		{Path: heroic, Source: "Heroic"},
+	}
+
+	for i := range expected {
+		expected[i].Path = resolvePath(expected[i].Path)
+	}
+
+	sort.Slice(expected, func(a, b int) bool {
+		return expected[a].Path < expected[b].Path
+	})
+
+	testza.AssertEqual(t, expected, discovered)
+}
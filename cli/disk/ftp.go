@@ -3,30 +3,112 @@ package disk
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"io/fs"
 	"log/slog"
+	"net"
 	"net/textproto"
 	"net/url"
+	"os"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/jackc/puddle/v2"
 	"github.com/jlaffaye/ftp"
-)
 
-// TODO Make configurable
-const connectionCount = 5
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
 
 var _ Disk = (*ftpDisk)(nil)
 
 type ftpDisk struct {
-	pool *puddle.Pool[*ftp.ServerConn]
-	path string
+	pool    *puddle.Pool[*ftp.ServerConn]
+	path    string
+	options ftpOptions
+}
+
+// ftpOptions are the connection settings of an ftp disk, configured with url query parameters
+type ftpOptions struct {
+	// dial-timeout: timeout for opening control and data connections
+	dialTimeout time.Duration
+
+	// keepalive: tcp keepalive period, connections idle for longer are checked with NOOP before use
+	keepAlive time.Duration
+
+	// pool-size: maximum number of concurrent connections
+	poolSize int32
+
+	// retries: how many times an operation is retried on a new connection if the connection dropped
+	retries int
+
+	// mode: "passive" uses EPSV with PASV fallback, "pasv" only uses PASV,
+	// "active" lets the server connect to the client announced with EPRT with PORT fallback
+	disableEPSV bool
+	active      bool
+}
+
+func parseFTPOptions(u *url.URL) (ftpOptions, error) {
+	options := ftpOptions{
+		poolSize:    5,
+		dialTimeout: time.Second * 5,
+		keepAlive:   time.Second * 30,
+		retries:     2,
+	}
+
+	query := u.Query()
+
+	if value := query.Get("pool-size"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || size < 1 {
+			return options, fmt.Errorf("invalid pool-size %s, expected a positive number", value)
+		}
+		options.poolSize = int32(size)
+	}
+
+	if value := query.Get("retries"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return options, fmt.Errorf("invalid retries %s, expected a non-negative number", value)
+		}
+		options.retries = retries
+	}
+
+	for name, target := range map[string]*time.Duration{
+		"dial-timeout": &options.dialTimeout,
+		"keepalive":    &options.keepAlive,
+	} {
+		if value := query.Get(name); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return options, fmt.Errorf("invalid %s %s, expected a duration such as 10s", name, value)
+			}
+			*target = duration
+		}
+	}
+
+	switch mode := query.Get("mode"); mode {
+	case "", "passive":
+	case "pasv":
+		options.disableEPSV = true
+	case "active":
+		// The data connections would have to be secured by the client, which jlaffaye/ftp only supports in passive mode
+		if u.Scheme == "ftps" || query.Get("tls") != "" {
+			return options, errors.New("active ftp mode is not supported with tls, use mode=passive or mode=pasv")
+		}
+		options.active = true
+	default:
+		return options, fmt.Errorf("unknown ftp mode %s, expected passive, pasv or active", mode)
+	}
+
+	return options, nil
 }
 
 type ftpEntry struct {
@@ -41,17 +123,65 @@ func (f ftpEntry) Name() string {
 	return f.Entry.Name
 }
 
+func (f ftpEntry) Size() int64 {
+	return int64(f.Entry.Size)
+}
+
+func (f ftpEntry) ModTime() time.Time {
+	return f.Entry.Time
+}
+
+func (f ftpEntry) Mode() fs.FileMode {
+	return entryMode(f.IsDir())
+}
+
 func newFTP(path string) (Disk, error) {
 	u, err := url.Parse(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ftp url: %w", err)
 	}
 
+	u, err = credentials.Resolve(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+
+	tlsOption, err := ftpTLSOption(u)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := parseFTPOptions(u)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{
+		Timeout:   options.dialTimeout,
+		KeepAlive: options.keepAlive,
+	}
+
+	// Every connection needs its own active dialer, as it tracks the data connections of its control connection
+	dialOptions := func() []ftp.DialOption {
+		dialOptions := []ftp.DialOption{
+			tlsOption,
+			ftp.DialWithDialer(dialer),
+			ftp.DialWithDisabledEPSV(options.disableEPSV),
+		}
+
+		if options.active {
+			activeDialer := &ftpActiveDialer{dialer: dialer}
+			dialOptions = append(dialOptions, ftp.DialWithDialFunc(activeDialer.dial))
+		}
+
+		return dialOptions
+	}
+
 	pool, err := puddle.NewPool(&puddle.Config[*ftp.ServerConn]{
 		Constructor: func(ctx context.Context) (*ftp.ServerConn, error) {
-			c, failedHidden, err := testFTP(u, ftp.DialWithTimeout(time.Second*5), ftp.DialWithForceListHidden(true))
+			c, failedHidden, err := testFTP(u, append(dialOptions(), ftp.DialWithForceListHidden(true))...)
 			if failedHidden {
-				c, _, err = testFTP(u, ftp.DialWithTimeout(time.Second*5))
+				c, _, err = testFTP(u, dialOptions()...)
 				if err != nil {
 					return nil, err
 				}
@@ -63,31 +193,106 @@ func newFTP(path string) (Disk, error) {
 
 			return c, nil
 		},
-		MaxSize: connectionCount,
+		Destructor: func(c *ftp.ServerConn) {
+			_ = c.Quit()
+		},
+		MaxSize: options.poolSize,
 	})
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to create ftp connection pool: %w", err)
 	}
 
 	return &ftpDisk{
-		path: u.Path,
-		pool: pool,
+		path:    u.Path,
+		pool:    pool,
+		options: options,
 	}, nil
 }
 
+// ftpTLSOption returns the dial option for the TLS mode of the url.
+//
+// ftps:// uses implicit TLS, ftp:// uses plain FTP unless the tls query parameter is "explicit" or "implicit".
+// Certificates are verified against the system roots, or the CA in the tls-ca query parameter.
+// Verification can be disabled for self-signed hosts with tls-insecure=true.
+func ftpTLSOption(u *url.URL) (ftp.DialOption, error) {
+	query := u.Query()
+
+	mode := query.Get("tls")
+	if u.Scheme == "ftps" {
+		if mode != "" && mode != "implicit" {
+			return ftp.DialOption{}, fmt.Errorf("ftps urls always use implicit tls, got tls=%s", mode)
+		}
+		mode = "implicit"
+	}
+
+	if mode == "" {
+		// A nil config keeps the connection in plain text
+		return ftp.DialWithTLS(nil), nil
+	}
+
+	if mode != "implicit" && mode != "explicit" {
+		return ftp.DialOption{}, fmt.Errorf("unknown tls mode %s, expected explicit or implicit", mode)
+	}
+
+	config := &tls.Config{
+		ServerName: u.Hostname(),
+		MinVersion: tls.VersionTLS12,
+		// Many servers require the data connections to resume the control connection session
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if caFile := query.Get("tls-ca"); caFile != "" {
+		caData, err := os.ReadFile(caFile)
+		if err != nil {
+			return ftp.DialOption{}, fmt.Errorf("failed to read tls ca: %w", err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caData) {
+			return ftp.DialOption{}, fmt.Errorf("no certificates found in tls ca %s", caFile)
+		}
+	}
+
+	if insecure, _ := strconv.ParseBool(query.Get("tls-insecure")); insecure {
+		slog.Warn("ftp tls certificate verification is disabled", slog.String("host", u.Hostname()))
+		config.InsecureSkipVerify = true
+	}
+
+	if mode == "explicit" {
+		return ftp.DialWithExplicitTLS(config), nil
+	}
+
+	return ftp.DialWithTLS(config), nil
+}
+
+// ftpAddress returns the host of the url with the default port for its tls mode
+func ftpAddress(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	if u.Scheme == "ftps" || u.Query().Get("tls") == "implicit" {
+		return net.JoinHostPort(u.Hostname(), "990")
+	}
+
+	return net.JoinHostPort(u.Hostname(), "21")
+}
+
 func testFTP(u *url.URL, options ...ftp.DialOption) (*ftp.ServerConn, bool, error) {
-	c, err := ftp.Dial(u.Host, options...)
+	c, err := ftp.Dial(ftpAddress(u), options...)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to dial host %s: %w", u.Host, err)
 	}
 
 	password, _ := u.User.Password()
 	if err := c.Login(u.User.Username(), password); err != nil {
+		_ = c.Quit()
 		return nil, false, fmt.Errorf("failed to login: %w", err)
 	}
 
 	_, err = c.List("/")
 	if err != nil {
+		_ = c.Quit()
 		return nil, true, fmt.Errorf("failed listing dir: %w", err)
 	}
 
@@ -174,141 +379,147 @@ func (l *ftpDisk) existsWithLock(res *puddle.Resource[*ftp.ServerConn], p string
 }
 
 func (l *ftpDisk) Exists(p string) (bool, error) {
-	res, err := l.acquire()
-	if err != nil {
-		return false, err
-	}
-
-	defer res.Release()
+	var exists bool
+	err := l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		var err error
+		exists, err = l.existsWithLock(res, p)
+		return err
+	})
 
-	return l.existsWithLock(res, p)
+	return exists, err
 }
 
 func (l *ftpDisk) Read(path string) ([]byte, error) {
-	res, err := l.acquire()
-	if err != nil {
-		return nil, err
-	}
+	var data []byte
+	err := l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 
-	defer res.Release()
+		f, err := res.Value().Retr(clean(path))
+		if err != nil {
+			var protocolError *textproto.Error
+			if errors.As(err, &protocolError) && protocolError.Code == ftp.StatusFileUnavailable {
+				return fmt.Errorf("failed to retrieve path: %w", os.ErrNotExist)
+			}
 
-	slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+			// Some servers answer missing files with other codes
+			if exists, existsErr := l.existsWithLock(res, path); existsErr == nil && !exists {
+				return fmt.Errorf("failed to retrieve path: %w", os.ErrNotExist)
+			}
 
-	f, err := res.Value().Retr(clean(path))
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve path: %w", err)
-	}
+			return fmt.Errorf("failed to retrieve path: %w", err)
+		}
 
-	defer f.Close()
+		defer f.Close()
 
-	data, err := io.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
+		data, err = io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
 
-	return data, nil
+		return nil
+	})
+
+	return data, err
 }
 
 func (l *ftpDisk) Write(path string, data []byte) error {
-	res, err := l.acquire()
-	if err != nil {
-		return err
-	}
-
-	defer res.Release()
-
-	slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
-	if err := res.Value().Stor(clean(path), bytes.NewReader(data)); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
+	return l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+		if err := res.Value().Stor(clean(path), bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (l *ftpDisk) Remove(path string) error {
-	res, err := l.acquire()
-	if err != nil {
-		return err
-	}
+	return l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+		if err := res.Value().Delete(clean(path)); err != nil {
+			if isConnectionError(err) {
+				return fmt.Errorf("failed to delete path: %w", err)
+			}
 
-	defer res.Release()
+			if err := res.Value().RemoveDirRecur(clean(path)); err != nil {
+				if isConnectionError(err) {
+					return fmt.Errorf("failed to delete path: %w", err)
+				}
 
-	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "ftp"))
-	if err := res.Value().Delete(clean(path)); err != nil {
-		if err := res.Value().RemoveDirRecur(clean(path)); err != nil {
-			return fmt.Errorf("failed to delete path: %w", err)
+				// Neither a file nor a directory, nothing to delete
+				exists, existsErr := l.existsWithLock(res, path)
+				if existsErr == nil && !exists {
+					return nil
+				}
+
+				return fmt.Errorf("failed to delete path: %w", err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 func (l *ftpDisk) MkDir(p string) error {
-	res, err := l.acquire()
-	if err != nil {
-		return err
-	}
-
-	defer res.Release()
+	return l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		lastExistingDir := clean(p)
+		for lastExistingDir != "/" && lastExistingDir != "." {
+			foundDir, err := l.existsWithLock(res, lastExistingDir)
+			if err != nil {
+				return err
+			}
 
-	lastExistingDir := clean(p)
-	for lastExistingDir != "/" && lastExistingDir != "." {
-		foundDir, err := l.existsWithLock(res, lastExistingDir)
-		if err != nil {
-			return err
-		}
+			if foundDir {
+				break
+			}
 
-		if foundDir {
-			break
+			lastExistingDir = path.Dir(lastExistingDir)
 		}
 
-		lastExistingDir = path.Dir(lastExistingDir)
-	}
+		remainingDirs := clean(p)
 
-	remainingDirs := clean(p)
-
-	if lastExistingDir != "/" && lastExistingDir != "." {
-		remainingDirs = strings.TrimPrefix(remainingDirs, lastExistingDir)
-	}
-
-	if len(remainingDirs) == 0 {
-		// Already exists
-		return nil
-	}
-
-	if err := res.Value().ChangeDir(lastExistingDir); err != nil {
-		return fmt.Errorf("failed to enter directory: %w", err)
-	}
+		if lastExistingDir != "/" && lastExistingDir != "." {
+			remainingDirs = strings.TrimPrefix(remainingDirs, lastExistingDir)
+		}
 
-	split := strings.Split(clean(remainingDirs)[1:], "/")
-	for _, s := range split {
-		slog.Debug("making directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
-		if err := res.Value().MakeDir(s); err != nil {
-			return fmt.Errorf("failed to make directory: %w", err)
+		if len(remainingDirs) == 0 {
+			// Already exists
+			return nil
 		}
 
-		slog.Debug("entering directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
-		if err := res.Value().ChangeDir(s); err != nil {
+		if err := res.Value().ChangeDir(lastExistingDir); err != nil {
 			return fmt.Errorf("failed to enter directory: %w", err)
 		}
-		lastExistingDir = path.Join(lastExistingDir, s)
-	}
 
-	return nil
+		split := strings.Split(clean(remainingDirs)[1:], "/")
+		for _, s := range split {
+			slog.Debug("making directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
+			if err := res.Value().MakeDir(s); err != nil {
+				return fmt.Errorf("failed to make directory: %w", err)
+			}
+
+			slog.Debug("entering directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
+			if err := res.Value().ChangeDir(s); err != nil {
+				return fmt.Errorf("failed to enter directory: %w", err)
+			}
+			lastExistingDir = path.Join(lastExistingDir, s)
+		}
+
+		return nil
+	})
 }
 
 func (l *ftpDisk) ReadDir(path string) ([]Entry, error) {
-	res, err := l.acquire()
+	var entries []Entry
+	err := l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		var err error
+		entries, err = l.readDirLock(res, path)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	defer res.Release()
-
-	entries, err := l.readDirLock(res, path)
-	if err != nil {
-		return nil, err
-	}
 	entries = slices.DeleteFunc(entries, func(i Entry) bool {
 		return i.Name() == "." || i.Name() == ".."
 	})
@@ -333,27 +544,131 @@ func (l *ftpDisk) readDirLock(res *puddle.Resource[*ftp.ServerConn], path string
 	return entries, nil
 }
 
-func (l *ftpDisk) Open(path string, _ int) (io.WriteCloser, error) {
+func (l *ftpDisk) Open(path string, flag int) (io.WriteCloser, error) {
+	if err := checkOpenFlags(l, path, flag); err != nil {
+		return nil, err
+	}
+
 	res, err := l.acquire()
 	if err != nil {
 		return nil, err
 	}
 
-	reader, writer := io.Pipe()
-
 	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 
-	go func() {
-		defer res.Release()
+	return newAsyncWriter(func(reader io.Reader) error {
+		var err error
+		if flag&os.O_APPEND != 0 {
+			err = res.Value().Append(clean(path), reader)
+		} else {
+			err = res.Value().Stor(clean(path), reader)
+		}
+
+		l.release(res, err)
 
-		err := res.Value().Stor(clean(path), reader)
 		if err != nil {
-			slog.Error("failed to store file", slog.Any("err", err))
+			return fmt.Errorf("failed to store file: %w", err)
 		}
+
 		slog.Debug("write success", slog.String("path", clean(path)), slog.String("schema", "ftp"))
-	}()
+		return nil
+	}), nil
+}
+
+func (l *ftpDisk) Stat(p string) (Entry, error) {
+	var entry Entry
+	err := l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		var err error
+		entry, err = l.statLock(res, p)
+		return err
+	})
+
+	return entry, err
+}
+
+func (l *ftpDisk) statLock(res *puddle.Resource[*ftp.ServerConn], p string) (Entry, error) {
+	slog.Debug("getting file info", slog.String("path", clean(p)), slog.String("schema", "ftp"))
+
+	var protocolError *textproto.Error
+
+	entry, err := res.Value().GetEntry(clean(p))
+	if err == nil {
+		// MLST returns the full path as the name
+		entry.Name = path.Base(clean(p))
+		return ftpEntry{
+			Entry: entry,
+		}, nil
+	}
 
-	return writer, nil
+	if !errors.As(err, &protocolError) {
+		return nil, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	switch protocolError.Code {
+	case ftp.StatusFileUnavailable:
+		return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+	case ftp.StatusNotImplemented:
+		// MLST is not supported, look for the path in its parent directory instead
+	default:
+		return nil, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	if clean(p) == "/" || clean(p) == "." {
+		return ftpEntry{
+			Entry: &ftp.Entry{
+				Name: clean(p),
+				Type: ftp.EntryTypeFolder,
+			},
+		}, nil
+	}
+
+	dir, err := l.readDirLock(res, path.Dir(clean(p)))
+	if err != nil {
+		if errors.As(err, &protocolError) && protocolError.Code == ftp.StatusFileUnavailable {
+			return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+		}
+
+		return nil, err
+	}
+
+	for _, entry := range dir {
+		if entry.Name() == path.Base(clean(p)) {
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+}
+
+func (l *ftpDisk) Rename(from string, to string) error {
+	return l.do(func(res *puddle.Resource[*ftp.ServerConn]) error {
+		slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "ftp"))
+
+		err := res.Value().Rename(clean(from), clean(to))
+		if err == nil {
+			return nil
+		}
+
+		if isConnectionError(err) {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+
+		// Some servers refuse to overwrite an existing file
+		target, statErr := l.statLock(res, to)
+		if statErr != nil || target.IsDir() {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+
+		if err := res.Value().Delete(clean(to)); err != nil {
+			return fmt.Errorf("failed to replace target: %w", err)
+		}
+
+		if err := res.Value().Rename(clean(from), clean(to)); err != nil {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+
+		return nil
+	})
 }
 
 func (l *ftpDisk) goHome(res *puddle.Resource[*ftp.ServerConn]) error {
@@ -367,15 +682,81 @@ func (l *ftpDisk) goHome(res *puddle.Resource[*ftp.ServerConn]) error {
 	return nil
 }
 
+// acquire returns a pooled connection in the root directory.
+// Connections that were idle for longer than the keepalive period are checked first,
+// and dropped connections are replaced with new ones.
 func (l *ftpDisk) acquire() (*puddle.Resource[*ftp.ServerConn], error) {
-	res, err := l.pool.Acquire(context.TODO())
-	if err != nil {
-		return nil, fmt.Errorf("failed acquiring connection: %w", err)
+	for attempt := 0; ; attempt++ {
+		res, err := l.pool.Acquire(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed acquiring connection: %w", err)
+		}
+
+		if res.IdleDuration() > l.options.keepAlive {
+			if err := res.Value().NoOp(); err != nil {
+				slog.Debug("dropping stale ftp connection", slog.Any("err", err))
+				res.Destroy()
+				continue
+			}
+		}
+
+		if err := l.goHome(res); err != nil {
+			if isConnectionError(err) && attempt < l.options.retries {
+				slog.Warn("ftp connection dropped, reconnecting", slog.Any("err", err))
+				res.Destroy()
+				continue
+			}
+
+			l.release(res, err)
+			return nil, err
+		}
+
+		return res, nil
 	}
+}
 
-	if err := l.goHome(res); err != nil {
-		return nil, err
+// release returns the connection to the pool, or closes it if it failed with a connection error
+func (l *ftpDisk) release(res *puddle.Resource[*ftp.ServerConn], err error) {
+	if err != nil && isConnectionError(err) {
+		res.Destroy()
+		return
+	}
+
+	res.Release()
+}
+
+// do runs fn with a pooled connection, retrying on a new connection if the connection dropped
+func (l *ftpDisk) do(fn func(res *puddle.Resource[*ftp.ServerConn]) error) error {
+	for attempt := 0; ; attempt++ {
+		res, err := l.acquire()
+		if err != nil {
+			return err
+		}
+
+		err = fn(res)
+		l.release(res, err)
+
+		if err != nil && isConnectionError(err) && attempt < l.options.retries {
+			slog.Warn("ftp connection dropped, retrying", slog.Any("err", err))
+			continue
+		}
+
+		return err
+	}
+}
+
+// isConnectionError returns true if the error means the connection is no longer usable
+func isConnectionError(err error) bool {
+	var protocolError *textproto.Error
+	if errors.As(err, &protocolError) {
+		return protocolError.Code == ftp.StatusNotAvailable
 	}
 
-	return res, nil
+	var netErr net.Error
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
 }
@@ -0,0 +1,320 @@
+package disk
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ftpActiveDialer connects jlaffaye/ftp, which only opens passive data connections, in active mode.
+//
+// The first dial is the control connection. Its EPSV and PASV commands are replaced with EPRT and PORT commands
+// for a local listener, and the replies are rewritten as if the server entered passive mode.
+// The following dials for data connections hand out the connection the server opens to that listener.
+type ftpActiveDialer struct {
+	control *ftpActiveConn
+	dialer  net.Dialer
+}
+
+func (d *ftpActiveDialer) dial(network string, address string) (net.Conn, error) {
+	if d.control != nil {
+		return d.control.dataConn()
+	}
+
+	conn, err := d.dialer.Dial(network, address)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	d.control = &ftpActiveConn{
+		Conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: d.dialer.Timeout,
+	}
+
+	return d.control, nil
+}
+
+// ftpActiveConn is the control connection of an active mode ftp connection
+type ftpActiveConn struct {
+	net.Conn
+	reader   *bufio.Reader
+	listener net.Listener
+	data     *ftpActiveDataConn
+
+	// command holds a partially written command line
+	command []byte
+
+	// replies holds rewritten reply lines that were not read yet
+	replies []byte
+
+	// held holds the lines of a multi-line reply to EPRT or PORT until its last line
+	held []byte
+
+	// passive is the reply the client expects for the pending EPRT or PORT command, empty if none is pending
+	passive string
+
+	timeout time.Duration
+}
+
+func (c *ftpActiveConn) Write(p []byte) (int, error) {
+	c.command = append(c.command, p...)
+
+	for {
+		end := bytes.Index(c.command, []byte("\r\n"))
+		if end == -1 {
+			return len(p), nil
+		}
+
+		line := string(c.command[:end])
+		c.command = c.command[end+2:]
+
+		switch strings.ToUpper(line) {
+		case "EPSV", "PASV":
+			var err error
+			line, err = c.listen(strings.EqualFold(line, "EPSV"))
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if _, err := io.WriteString(c.Conn, line+"\r\n"); err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+	}
+}
+
+// listen opens the listener for the next data connection and returns the command announcing it to the server
+func (c *ftpActiveConn) listen(extended bool) (string, error) {
+	c.closeListener()
+
+	local := c.Conn.LocalAddr().(*net.TCPAddr)
+
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: local.IP})
+	if err != nil {
+		return "", fmt.Errorf("failed to listen for active data connection: %w", err)
+	}
+
+	c.listener = listener
+	c.data = nil
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if extended {
+		family := 1
+		if local.IP.To4() == nil {
+			family = 2
+		}
+
+		c.passive = fmt.Sprintf("229 Entering Extended Passive Mode (|||%d|)", port)
+		return fmt.Sprintf("EPRT |%d|%s|%d|", family, local.IP.String(), port), nil
+	}
+
+	ip := local.IP.To4()
+	if ip == nil {
+		c.closeListener()
+		return "", errors.New("PORT requires an ipv4 control connection")
+	}
+
+	c.passive = fmt.Sprintf("227 Entering Passive Mode (%d,%d,%d,%d,%d,%d)", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff)
+	return fmt.Sprintf("PORT %d,%d,%d,%d,%d,%d", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff), nil
+}
+
+func (c *ftpActiveConn) Read(p []byte) (int, error) {
+	for len(c.replies) == 0 {
+		line, err := c.reader.ReadString('\n')
+		if line == "" && err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+
+		c.replies = append(c.replies, c.rewriteReply(line)...)
+	}
+
+	n := copy(p, c.replies)
+	c.replies = c.replies[n:]
+
+	return n, nil
+}
+
+// rewriteReply turns a successful reply to EPRT or PORT into the passive mode reply the client expects
+func (c *ftpActiveConn) rewriteReply(line string) string {
+	if len(line) < 4 {
+		return line
+	}
+
+	code := line[:3]
+
+	if c.passive == "" {
+		// The transfer started, so the server will use the data connection
+		if c.data != nil && (code == "125" || code == "150") {
+			c.data.started = true
+		}
+
+		return line
+	}
+
+	if line[3] == '-' {
+		c.held = append(c.held, line...)
+		return ""
+	}
+
+	passive := c.passive
+	held := string(c.held)
+	c.passive = ""
+	c.held = nil
+
+	if code[0] == '2' {
+		return passive + "\r\n"
+	}
+
+	// Refused, the client falls back to PASV or fails the transfer
+	c.closeListener()
+
+	return held + line
+}
+
+// dataConn hands out the data connection announced by the last EPRT or PORT command
+func (c *ftpActiveConn) dataConn() (net.Conn, error) {
+	if c.listener == nil {
+		return nil, errors.New("no active data connection was announced")
+	}
+
+	c.data = &ftpActiveDataConn{
+		listener: c.listener,
+		timeout:  c.timeout,
+	}
+	c.listener = nil
+
+	return c.data, nil
+}
+
+func (c *ftpActiveConn) closeListener() {
+	if c.listener != nil {
+		_ = c.listener.Close()
+		c.listener = nil
+	}
+}
+
+func (c *ftpActiveConn) Close() error {
+	c.closeListener()
+	return c.Conn.Close() //nolint:wrapcheck
+}
+
+// ftpActiveDataConn accepts the connection of the server on first use,
+// as the server only connects once the transfer command was sent
+type ftpActiveDataConn struct {
+	listener net.Listener
+	conn     net.Conn
+	err      error
+	deadline time.Time
+	timeout  time.Duration
+	once     sync.Once
+
+	// started is set once the server replied that the transfer started
+	started bool
+}
+
+func (c *ftpActiveDataConn) accept() error {
+	c.once.Do(func() {
+		defer c.listener.Close()
+
+		if listener, ok := c.listener.(*net.TCPListener); ok && c.timeout > 0 {
+			_ = listener.SetDeadline(time.Now().Add(c.timeout))
+		}
+
+		conn, err := c.listener.Accept()
+		if err != nil {
+			c.err = fmt.Errorf("server did not open the active data connection: %w", err)
+			return
+		}
+
+		if !c.deadline.IsZero() {
+			_ = conn.SetDeadline(c.deadline)
+		}
+
+		c.conn = conn
+	})
+
+	return c.err
+}
+
+func (c *ftpActiveDataConn) Read(p []byte) (int, error) {
+	if err := c.accept(); err != nil {
+		return 0, err
+	}
+
+	return c.conn.Read(p) //nolint:wrapcheck
+}
+
+func (c *ftpActiveDataConn) Write(p []byte) (int, error) {
+	if err := c.accept(); err != nil {
+		return 0, err
+	}
+
+	return c.conn.Write(p) //nolint:wrapcheck
+}
+
+// Handshake is called by jlaffaye/ftp for uploads without data, so the server's connection is still taken
+func (c *ftpActiveDataConn) Handshake() error {
+	return c.accept()
+}
+
+func (c *ftpActiveDataConn) Close() error {
+	if !c.started {
+		// The transfer command failed, the server will not connect
+		c.once.Do(func() {
+			c.err = net.ErrClosed
+		})
+		return c.listener.Close() //nolint:wrapcheck
+	}
+
+	if err := c.accept(); err != nil {
+		return err
+	}
+
+	return c.conn.Close() //nolint:wrapcheck
+}
+
+func (c *ftpActiveDataConn) LocalAddr() net.Addr {
+	if c.conn != nil {
+		return c.conn.LocalAddr()
+	}
+	return c.listener.Addr()
+}
+
+func (c *ftpActiveDataConn) RemoteAddr() net.Addr {
+	if c.conn != nil {
+		return c.conn.RemoteAddr()
+	}
+	return c.listener.Addr()
+}
+
+func (c *ftpActiveDataConn) SetDeadline(t time.Time) error {
+	if c.conn != nil {
+		return c.conn.SetDeadline(t) //nolint:wrapcheck
+	}
+	c.deadline = t
+	return nil
+}
+
+func (c *ftpActiveDataConn) SetReadDeadline(t time.Time) error {
+	if c.conn != nil {
+		return c.conn.SetReadDeadline(t) //nolint:wrapcheck
+	}
+	c.deadline = t
+	return nil
+}
+
+func (c *ftpActiveDataConn) SetWriteDeadline(t time.Time) error {
+	if c.conn != nil {
+		return c.conn.SetWriteDeadline(t) //nolint:wrapcheck
+	}
+	c.deadline = t
+	return nil
+}
@@ -14,7 +14,7 @@ type localDisk struct {
 }
 
 type localEntry struct {
-	os.DirEntry
+	os.FileInfo
 }
 
 func newLocal(path string) (Disk, error) {
@@ -59,8 +59,13 @@ func (l localDisk) ReadDir(path string) ([]Entry, error) {
 
 	entries := make([]Entry, len(dir))
 	for i, entry := range dir {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+
 		entries[i] = localEntry{
-			DirEntry: entry,
+			FileInfo: info,
 		}
 	}
 
@@ -70,3 +75,18 @@ func (l localDisk) ReadDir(path string) ([]Entry, error) {
 func (l localDisk) Open(path string, flag int) (io.WriteCloser, error) {
 	return os.OpenFile(path, flag, 0o777) //nolint
 }
+
+func (l localDisk) Stat(path string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	return localEntry{
+		FileInfo: info,
+	}, nil
+}
+
+func (l localDisk) Rename(from string, to string) error {
+	return os.Rename(from, to) //nolint
+}
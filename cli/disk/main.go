@@ -1,11 +1,15 @@
 package disk
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net/url"
+	"os"
 	"path/filepath"
+	"time"
 )
 
 type Disk interface {
@@ -14,13 +18,15 @@ type Disk interface {
 
 	// Read returns the entire file as a byte buffer
 	//
-	// Returns error if provided path is not a file
+	// Returns error if provided path is not a file, wrapping os.ErrNotExist if it does not exist
 	Read(path string) ([]byte, error)
 
 	// Write writes provided byte buffer to the path
 	Write(path string, data []byte) error
 
 	// Remove deletes the provided file or directory recursively
+	//
+	// Removing a path that does not exist is not an error
 	Remove(path string) error
 
 	// MkDir creates the provided directory recursively
@@ -32,12 +38,37 @@ type Disk interface {
 	ReadDir(path string) ([]Entry, error)
 
 	// Open opens provided path for writing
+	//
+	// Honors os.O_CREATE, os.O_EXCL and os.O_APPEND. Remote disks always replace the whole file unless appending.
 	Open(path string, flag int) (io.WriteCloser, error)
+
+	// Stat returns information about the provided file or directory
+	//
+	// Returns an error wrapping os.ErrNotExist if the path does not exist
+	Stat(path string) (Entry, error)
+
+	// Rename moves the provided file or directory, replacing the target file if it exists
+	Rename(from string, to string) error
+}
+
+// ErrRemoteExtractUnsupported is returned by ArchiveExtractor when the archive has to be extracted locally
+var ErrRemoteExtractUnsupported = errors.New("remote extraction is not supported")
+
+// ArchiveExtractor is implemented by disks that can extract zip archives on the remote side,
+// avoiding a round trip for every file in the archive
+type ArchiveExtractor interface {
+	// ExtractArchive uploads the zip archive and extracts it into the target directory.
+	//
+	// Returns ErrRemoteExtractUnsupported without changing anything if the archive can not be extracted remotely
+	ExtractArchive(archive io.Reader, target string) error
 }
 
 type Entry interface {
 	IsDir() bool
 	Name() string
+	Size() int64
+	ModTime() time.Time
+	Mode() fs.FileMode
 }
 
 func FromPath(path string) (Disk, error) {
@@ -47,19 +78,104 @@ func FromPath(path string) (Disk, error) {
 	}
 
 	switch parsed.Scheme {
-	case "ftp":
+	case "ftp", "ftps":
 		slog.Info("connecting to ftp")
 		return newFTP(path)
 	case "sftp":
 		slog.Info("connecting to sftp")
 		return newSFTP(path)
+	case "webdav", "webdavs":
+		slog.Info("connecting to webdav")
+		return newWebDAV(path)
+	case "s3":
+		slog.Info("connecting to s3")
+		return newS3(path)
 	}
 
 	slog.Info("using local disk", slog.String("path", path))
 	return newLocal(path)
 }
 
+// IsRemote returns true if the provided path refers to a non-local disk
+func IsRemote(path string) bool {
+	parsed, err := url.Parse(path)
+	if err != nil {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "ftp", "ftps", "sftp", "webdav", "webdavs", "s3":
+		return true
+	}
+
+	return false
+}
+
 // clean returns a unix-style path
 func clean(path string) string {
 	return filepath.ToSlash(filepath.Clean(path))
 }
+
+// entryMode returns the mode reported for entries of disks without permissions
+func entryMode(isDir bool) fs.FileMode {
+	if isDir {
+		return fs.ModeDir | 0o755
+	}
+
+	return 0o644
+}
+
+// checkOpenFlags validates the open flags against the existence of the path,
+// for disks that can not open files natively
+func checkOpenFlags(d Disk, path string, flag int) error {
+	if flag&os.O_CREATE != 0 && flag&os.O_EXCL == 0 {
+		return nil
+	}
+
+	exists, err := d.Exists(path)
+	if err != nil {
+		return err
+	}
+
+	if flag&os.O_CREATE == 0 && !exists {
+		return fmt.Errorf("failed to open %s: %w", clean(path), os.ErrNotExist)
+	}
+
+	if flag&os.O_EXCL != 0 && exists {
+		return fmt.Errorf("failed to open %s: %w", clean(path), os.ErrExist)
+	}
+
+	return nil
+}
+
+// asyncWriter streams writes to an upload running in the background
+type asyncWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+// newAsyncWriter starts upload with a reader of everything written to the returned writer
+func newAsyncWriter(upload func(reader io.Reader) error) io.WriteCloser {
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		err := upload(reader)
+		_ = reader.CloseWithError(err)
+		done <- err
+	}()
+
+	return asyncWriter{
+		PipeWriter: writer,
+		done:       done,
+	}
+}
+
+// Close finishes the upload and returns its result
+func (w asyncWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close upload: %w", err)
+	}
+
+	return <-w.done
+}
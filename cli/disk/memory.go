@@ -0,0 +1,282 @@
+package disk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Disk = (*memoryDisk)(nil)
+
+// memoryDisk keeps every file in memory. It is meant for tests that do not need a real file system.
+type memoryDisk struct {
+	files map[string]*memoryFile
+	mux   sync.RWMutex
+}
+
+type memoryFile struct {
+	modTime time.Time
+	data    []byte
+	isDir   bool
+}
+
+type memoryEntry struct {
+	modTime time.Time
+	name    string
+	size    int64
+	isDir   bool
+}
+
+func (e memoryEntry) IsDir() bool {
+	return e.isDir
+}
+
+func (e memoryEntry) Name() string {
+	return e.name
+}
+
+func (e memoryEntry) Size() int64 {
+	return e.size
+}
+
+func (e memoryEntry) ModTime() time.Time {
+	return e.modTime
+}
+
+func (e memoryEntry) Mode() fs.FileMode {
+	return entryMode(e.isDir)
+}
+
+// NewMemory returns an empty in-memory disk
+func NewMemory() Disk {
+	now := time.Now()
+
+	return &memoryDisk{
+		files: map[string]*memoryFile{
+			"/": {isDir: true, modTime: now},
+			".": {isDir: true, modTime: now},
+		},
+	}
+}
+
+func (l *memoryDisk) Exists(p string) (bool, error) {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	_, ok := l.files[clean(p)]
+	return ok, nil
+}
+
+func (l *memoryDisk) Read(p string) ([]byte, error) {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	file, ok := l.files[clean(p)]
+	if !ok {
+		return nil, fmt.Errorf("failed to read %s: %w", clean(p), os.ErrNotExist)
+	}
+
+	if file.isDir {
+		return nil, fmt.Errorf("failed to read %s: is a directory", clean(p))
+	}
+
+	return bytes.Clone(file.data), nil
+}
+
+func (l *memoryDisk) Write(p string, data []byte) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.writeLock(clean(p), bytes.Clone(data))
+}
+
+func (l *memoryDisk) writeLock(p string, data []byte) error {
+	parent, ok := l.files[path.Dir(p)]
+	if !ok || !parent.isDir {
+		return fmt.Errorf("failed to write %s: %w", p, os.ErrNotExist)
+	}
+
+	if file, ok := l.files[p]; ok && file.isDir {
+		return fmt.Errorf("failed to write %s: is a directory", p)
+	}
+
+	l.files[p] = &memoryFile{
+		data:    data,
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+func (l *memoryDisk) Remove(p string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for _, name := range l.treeLock(clean(p)) {
+		delete(l.files, name)
+	}
+
+	return nil
+}
+
+// treeLock returns the path and every path below it
+func (l *memoryDisk) treeLock(p string) []string {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+
+	tree := make([]string, 0)
+	for name := range l.files {
+		if name == p || strings.HasPrefix(name, prefix) {
+			tree = append(tree, name)
+		}
+	}
+
+	return tree
+}
+
+func (l *memoryDisk) MkDir(p string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	missing := make([]string, 0)
+
+	current := clean(p)
+	for {
+		file, ok := l.files[current]
+		if ok {
+			if !file.isDir {
+				return fmt.Errorf("failed to make directory %s: %s is a file", clean(p), current)
+			}
+
+			break
+		}
+
+		missing = append(missing, current)
+		current = path.Dir(current)
+	}
+
+	for _, dir := range missing {
+		l.files[dir] = &memoryFile{
+			isDir:   true,
+			modTime: time.Now(),
+		}
+	}
+
+	return nil
+}
+
+func (l *memoryDisk) ReadDir(p string) ([]Entry, error) {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	dir, ok := l.files[clean(p)]
+	if !ok {
+		return nil, fmt.Errorf("failed to list files in directory: %w", os.ErrNotExist)
+	}
+
+	if !dir.isDir {
+		return nil, fmt.Errorf("failed to list files in directory: %s is not a directory", clean(p))
+	}
+
+	entries := make([]Entry, 0)
+	for name, file := range l.files {
+		if name != clean(p) && path.Dir(name) == clean(p) {
+			entries = append(entries, file.entry(path.Base(name)))
+		}
+	}
+
+	return entries, nil
+}
+
+func (f *memoryFile) entry(name string) memoryEntry {
+	return memoryEntry{
+		name:    name,
+		size:    int64(len(f.data)),
+		modTime: f.modTime,
+		isDir:   f.isDir,
+	}
+}
+
+type memoryWriter struct {
+	buffer *bytes.Buffer
+	disk   *memoryDisk
+	path   string
+}
+
+func (w memoryWriter) Write(p []byte) (int, error) {
+	return w.buffer.Write(p) //nolint:wrapcheck
+}
+
+// Close stores the written data as the contents of the file
+func (w memoryWriter) Close() error {
+	w.disk.mux.Lock()
+	defer w.disk.mux.Unlock()
+
+	return w.disk.writeLock(w.path, w.buffer.Bytes())
+}
+
+func (l *memoryDisk) Open(p string, flag int) (io.WriteCloser, error) {
+	if err := checkOpenFlags(l, p, flag); err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if flag&os.O_APPEND != 0 {
+		l.mux.RLock()
+		if file, ok := l.files[clean(p)]; ok {
+			buffer.Write(file.data)
+		}
+		l.mux.RUnlock()
+	}
+
+	return memoryWriter{
+		buffer: buffer,
+		disk:   l,
+		path:   clean(p),
+	}, nil
+}
+
+func (l *memoryDisk) Stat(p string) (Entry, error) {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	file, ok := l.files[clean(p)]
+	if !ok {
+		return nil, fmt.Errorf("failed to stat path: %w", os.ErrNotExist)
+	}
+
+	return file.entry(path.Base(clean(p))), nil
+}
+
+func (l *memoryDisk) Rename(from string, to string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	from = clean(from)
+	to = clean(to)
+
+	if _, ok := l.files[from]; !ok {
+		return fmt.Errorf("failed to rename path: %w", os.ErrNotExist)
+	}
+
+	if parent, ok := l.files[path.Dir(to)]; !ok || !parent.isDir {
+		return fmt.Errorf("failed to rename path: %w", os.ErrNotExist)
+	}
+
+	if target, ok := l.files[to]; ok && target.isDir {
+		return fmt.Errorf("failed to rename path: %s is a directory", to)
+	}
+
+	for _, name := range l.treeLock(from) {
+		l.files[to+strings.TrimPrefix(name, from)] = l.files[name]
+		delete(l.files, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	miniocredentials "github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+var _ Disk = (*s3Disk)(nil)
+
+// s3Disk stores files as objects in a bucket. Directories only exist as key prefixes.
+type s3Disk struct {
+	client *minio.Client
+	bucket string
+}
+
+type s3Entry struct {
+	modTime time.Time
+	name    string
+	size    int64
+	isDir   bool
+}
+
+func (e s3Entry) IsDir() bool {
+	return e.isDir
+}
+
+func (e s3Entry) Name() string {
+	return e.name
+}
+
+func (e s3Entry) Size() int64 {
+	return e.size
+}
+
+func (e s3Entry) ModTime() time.Time {
+	return e.modTime
+}
+
+func (e s3Entry) Mode() fs.FileMode {
+	return entryMode(e.isDir)
+}
+
+// newS3 creates a disk for s3://bucket/prefix urls.
+//
+// Supported query parameters:
+//   - endpoint: base url of an S3-compatible service, defaults to AWS
+//   - region: signing region, defaults to AWS_REGION or us-east-1
+//   - path-style: address the bucket in the path instead of the hostname, defaults to true for custom endpoints
+//
+// The access key and secret key are taken from the url user info (or a stored credential),
+// falling back to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func newS3(p string) (Disk, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse s3 url: %w", err)
+	}
+
+	u, err = credentials.Resolve(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("s3 url is missing the bucket name")
+	}
+
+	query := u.Query()
+
+	region := query.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := query.Get("endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+
+	pathStyle := endpoint != ""
+	if value := query.Get("path-style"); value != "" {
+		pathStyle, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path-style %s: %w", value, err)
+		}
+	}
+
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse s3 endpoint: %w", err)
+	}
+
+	if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported s3 endpoint scheme: %s", endpointURL.Scheme)
+	}
+
+	if strings.Trim(endpointURL.Path, "/") != "" {
+		return nil, fmt.Errorf("s3 endpoints with a path are not supported: %s", endpoint)
+	}
+
+	accessKey := u.User.Username()
+	secretKey, _ := u.User.Password()
+	sessionToken := ""
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("no s3 credentials, provide them in the url, a stored credential or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	lookup := minio.BucketLookupDNS
+	if pathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpointURL.Host, &minio.Options{
+		Creds:        miniocredentials.NewStaticV4(accessKey, secretKey, sessionToken),
+		Secure:       endpointURL.Scheme == "https",
+		Region:       region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return s3Disk{
+		client: client,
+		bucket: u.Host,
+	}, nil
+}
+
+// key returns the object key of a disk path
+func (l s3Disk) key(p string) string {
+	return strings.Trim(clean(p), "/")
+}
+
+// isS3NotFound reports whether the object or key does not exist
+func isS3NotFound(err error) bool {
+	response := minio.ToErrorResponse(err)
+	return response.StatusCode == http.StatusNotFound || response.Code == "NoSuchKey"
+}
+
+// hasObjectsBelow reports whether a directory exists, which is the case as long as there is an object below it
+func (l s3Disk) hasObjectsBelow(key string) (bool, error) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	for object := range l.client.ListObjects(ctx, l.bucket, minio.ListObjectsOptions{
+		Prefix:    key + "/",
+		Recursive: true,
+		MaxKeys:   1,
+	}) {
+		if object.Err != nil {
+			return false, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// keysBelow returns the keys of all objects with the prefix
+func (l s3Disk) keysBelow(prefix string) ([]string, error) {
+	keys := make([]string, 0)
+
+	for object := range l.client.ListObjects(context.TODO(), l.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+
+		keys = append(keys, object.Key)
+	}
+
+	return keys, nil
+}
+
+// removeKeys deletes the objects in batches, keys that do not exist are ignored
+func (l s3Disk) removeKeys(keys []string) error {
+	objects := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objects <- minio.ObjectInfo{Key: key}
+	}
+	close(objects)
+
+	var err error
+	for removeErr := range l.client.RemoveObjects(context.TODO(), l.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if err == nil {
+			err = fmt.Errorf("failed to delete %s: %w", removeErr.ObjectName, removeErr.Err)
+		}
+	}
+
+	return err
+}
+
+func (l s3Disk) Exists(p string) (bool, error) {
+	slog.Debug("checking if file exists", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	key := l.key(p)
+	if key == "" {
+		return true, nil
+	}
+
+	_, err := l.client.StatObject(context.TODO(), l.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+
+	if !isS3NotFound(err) {
+		return false, fmt.Errorf("failed to check if file exists: %w", err)
+	}
+
+	return l.hasObjectsBelow(key)
+}
+
+func (l s3Disk) Read(p string) ([]byte, error) {
+	slog.Debug("reading file", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	object, err := l.client.GetObject(context.TODO(), l.bucket, l.key(p), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve path: %w", err)
+	}
+
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("failed to retrieve path: %w", os.ErrNotExist)
+		}
+
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (l s3Disk) Write(p string, data []byte) error {
+	slog.Debug("writing to file", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	_, err := l.client.PutObject(context.TODO(), l.bucket, l.key(p), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		PartSize: s3PartSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (l s3Disk) Remove(p string) error {
+	slog.Debug("deleting path", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	key := l.key(p)
+
+	prefix := ""
+	if key != "" {
+		prefix = key + "/"
+	}
+
+	keys, err := l.keysBelow(prefix)
+	if err != nil {
+		return err
+	}
+
+	if key != "" {
+		keys = append(keys, key)
+	}
+
+	return l.removeKeys(keys)
+}
+
+// MkDir does nothing, directories are implied by the keys of the objects within them
+func (l s3Disk) MkDir(p string) error {
+	slog.Debug("making directory", slog.String("path", clean(p)), slog.String("schema", "s3"))
+	return nil
+}
+
+func (l s3Disk) ReadDir(p string) ([]Entry, error) {
+	slog.Debug("reading directory", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	prefix := l.key(p)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	entries := make([]Entry, 0)
+
+	for object := range l.client.ListObjects(context.TODO(), l.bucket, minio.ListObjectsOptions{
+		Prefix: prefix,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+
+		name := strings.TrimPrefix(object.Key, prefix)
+		if name == "" {
+			// Directory marker object
+			continue
+		}
+
+		if strings.HasSuffix(name, "/") {
+			entries = append(entries, s3Entry{
+				name:  strings.TrimSuffix(name, "/"),
+				isDir: true,
+			})
+			continue
+		}
+
+		entries = append(entries, s3Entry{
+			name:    name,
+			size:    object.Size,
+			modTime: object.LastModified,
+		})
+	}
+
+	return entries, nil
+}
+
+func (l s3Disk) Stat(p string) (Entry, error) {
+	slog.Debug("getting file info", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	key := l.key(p)
+	if key == "" {
+		return s3Entry{name: "/", isDir: true}, nil
+	}
+
+	object, err := l.client.StatObject(context.TODO(), l.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return s3Entry{
+			name:    path.Base(key),
+			size:    object.Size,
+			modTime: object.LastModified,
+		}, nil
+	}
+
+	if !isS3NotFound(err) {
+		return nil, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	isDir, err := l.hasObjectsBelow(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isDir {
+		return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+	}
+
+	return s3Entry{
+		name:  path.Base(key),
+		isDir: true,
+	}, nil
+}
+
+// Rename copies every object to the new key and deletes the original, S3 has no native move
+func (l s3Disk) Rename(from string, to string) error {
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "s3"))
+
+	fromKey := l.key(from)
+	toKey := l.key(to)
+
+	entry, err := l.Stat(from)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{fromKey}
+	if entry.IsDir() {
+		keys, err = l.keysBelow(fromKey + "/")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, objectKey := range keys {
+		_, err := l.client.CopyObject(context.TODO(), minio.CopyDestOptions{
+			Bucket: l.bucket,
+			Object: toKey + strings.TrimPrefix(objectKey, fromKey),
+		}, minio.CopySrcOptions{
+			Bucket: l.bucket,
+			Object: objectKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", objectKey, err)
+		}
+	}
+
+	return l.removeKeys(keys)
+}
+
+// s3PartSize is the size of the parts of multipart uploads, S3 requires at least 5 MiB.
+//
+// Files opened for writing are streamed in parts of this size, so they never have to be held in memory completely.
+var s3PartSize uint64 = 16 << 20
+
+// Open streams the written data to the object when closed. S3 has no conditional create for every service,
+// so O_EXCL relies on the existence check.
+func (l s3Disk) Open(p string, flag int) (io.WriteCloser, error) {
+	if err := checkOpenFlags(l, p, flag); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("opening for writing", slog.String("path", clean(p)), slog.String("schema", "s3"))
+
+	key := l.key(p)
+
+	var existing io.ReadCloser
+	if flag&os.O_APPEND != 0 {
+		// Objects can not be appended to, so the current contents are uploaded again in front of the new data
+		exists, err := l.Exists(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			existing, err = l.client.GetObject(context.TODO(), l.bucket, key, minio.GetObjectOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve path: %w", err)
+			}
+		}
+	}
+
+	return newAsyncWriter(func(reader io.Reader) error {
+		if existing != nil {
+			defer existing.Close()
+			reader = io.MultiReader(existing, reader)
+		}
+
+		_, err := l.client.PutObject(context.TODO(), l.bucket, key, reader, -1, minio.PutObjectOptions{
+			PartSize: s3PartSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		return nil
+	}), nil
+}
@@ -0,0 +1,474 @@
+package disk
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible service using path-style addressing
+type fakeS3 struct {
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	t       *testing.T
+	bucket  string
+	secret  string
+
+	// parts is the number of parts of the last completed multipart upload
+	parts int
+
+	// deletes counts the objects deleted one by one instead of in a batch
+	deletes int
+
+	mux sync.Mutex
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if !f.verify(r) {
+		f.error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	bucketPrefix := "/" + f.bucket
+	if !strings.HasPrefix(r.URL.Path, bucketPrefix) {
+		f.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, bucketPrefix), "/")
+	query := r.URL.Query()
+
+	body, err := f.body(r)
+	testza.AssertNoError(f.t, err)
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		if f.uploads == nil {
+			f.uploads = map[string]map[int][]byte{}
+		}
+		uploadID := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[uploadID] = map[int][]byte{}
+		f.xml(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string   `xml:"Bucket"`
+			Key      string   `xml:"Key"`
+			UploadID string   `xml:"UploadId"`
+		}{Bucket: f.bucket, Key: key, UploadID: uploadID})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		testza.AssertNoError(f.t, err)
+		parts[partNumber] = body
+		w.Header().Set("ETag", `"part-`+query.Get("partNumber")+`"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var complete struct {
+			Parts []struct {
+				ETag       string `xml:"ETag"`
+				PartNumber int    `xml:"PartNumber"`
+			} `xml:"Part"`
+		}
+		testza.AssertNoError(f.t, xml.Unmarshal(body, &complete))
+		var data []byte
+		for _, part := range complete.Parts {
+			testza.AssertEqual(f.t, "part-"+strconv.Itoa(part.PartNumber), strings.Trim(part.ETag, `"`))
+			data = append(data, parts[part.PartNumber]...)
+		}
+		f.objects[key] = data
+		f.parts = len(complete.Parts)
+		delete(f.uploads, query.Get("uploadId"))
+		f.xml(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string   `xml:"Bucket"`
+			Key     string   `xml:"Key"`
+			ETag    string   `xml:"ETag"`
+		}{Bucket: f.bucket, Key: key, ETag: `"complete"`})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && query.Has("delete"):
+		var request struct {
+			Objects []struct {
+				Key string `xml:"Key"`
+			} `xml:"Object"`
+		}
+		testza.AssertNoError(f.t, xml.Unmarshal(body, &request))
+		for _, object := range request.Objects {
+			delete(f.objects, object.Key)
+		}
+		f.xml(w, struct {
+			XMLName xml.Name `xml:"DeleteResult"`
+		}{})
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, r)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"object"`)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		sourceKey, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		testza.AssertNoError(f.t, err)
+		source, ok := f.objects[strings.TrimPrefix(strings.TrimPrefix(sourceKey, "/"), f.bucket+"/")]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		f.objects[key] = source
+		f.xml(w, struct {
+			XMLName      xml.Name  `xml:"CopyObjectResult"`
+			ETag         string    `xml:"ETag"`
+			LastModified time.Time `xml:"LastModified"`
+		}{ETag: `"object"`, LastModified: time.Now().UTC()})
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("ETag", `"object"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		f.deletes++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) xml(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	testza.AssertNoError(f.t, xml.NewEncoder(w).Encode(result))
+}
+
+func (f *fakeS3) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, "<Error><Code>"+code+"</Code></Error>")
+}
+
+// body returns the payload of the request, decoding the chunks of streaming uploads
+func (f *fakeS3) body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body) //nolint:wrapcheck
+	}
+
+	reader := bufio.NewReader(r.Body)
+
+	var data []byte
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(strings.Split(header, ";")[0]), 16, 64)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		if size == 0 {
+			return data, nil
+		}
+
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		data = append(data, chunk[:size]...)
+	}
+}
+
+// verify recomputes the AWS Signature Version 4 of the request as it was received
+func (f *fakeS3) verify(r *http.Request) bool {
+	authorization := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+
+	fields := map[string]string{}
+	for _, field := range strings.Split(authorization, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		fields[name] = value
+	}
+
+	scope := strings.SplitN(fields["Credential"], "/", 2)
+	if len(scope) != 2 || scope[0] != "access" {
+		return false
+	}
+
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	headers := strings.Split(fields["SignedHeaders"], ";")
+	canonicalHeaders := ""
+	for _, name := range headers {
+		value := r.Host
+		if name != "host" {
+			value = strings.Join(r.Header.Values(name), ",")
+		}
+		canonicalHeaders += name + ":" + strings.TrimSpace(value) + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		s3utils.EncodePath(r.URL.Path),
+		strings.ReplaceAll(r.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders,
+		fields["SignedHeaders"],
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope[1] + "\n" + hex.EncodeToString(hash[:])
+
+	return signer.PostPresignSignatureV4(stringToSign, date, f.secret, "us-east-1") == fields["Signature"]
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	delimiter := r.URL.Query().Get("delimiter")
+
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int    `xml:"Size"`
+	}
+
+	type commonPrefix struct {
+		Prefix string `xml:"Prefix"`
+	}
+
+	result := struct {
+		XMLName        xml.Name       `xml:"ListBucketResult"`
+		Contents       []content      `xml:"Contents"`
+		CommonPrefixes []commonPrefix `xml:"CommonPrefixes"`
+		IsTruncated    bool           `xml:"IsTruncated"`
+	}{}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seenPrefixes := map[string]bool{}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(key, prefix)
+		if delimiter != "" && strings.Contains(rest, delimiter) {
+			common := prefix + rest[:strings.Index(rest, delimiter)+1]
+			if !seenPrefixes[common] {
+				seenPrefixes[common] = true
+				result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: common})
+			}
+			continue
+		}
+
+		result.Contents = append(result.Contents, content{Key: key, Size: len(f.objects[key])})
+	}
+
+	f.xml(w, result)
+}
+
+// startFakeS3 serves an empty "images" bucket, accepting the access key "access" with the secret "secret"
+func startFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(&fakeS3{
+		t:       t,
+		bucket:  "images",
+		secret:  "secret",
+		objects: map[string][]byte{},
+	})
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{
+		t:       t,
+		bucket:  "images",
+		secret:  "secret/key+value",
+		objects: map[string][]byte{},
+	}
+
+	s := httptest.NewServer(fake)
+	defer s.Close()
+
+	d, err := FromPath("s3://access:" + "secret%2Fkey+value" + "@images/server?endpoint=" + s.URL)
+	testza.AssertNoError(t, err)
+
+	exists, err := d.Exists("/server/FactoryGame/Mods")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	testza.AssertNoError(t, d.MkDir("/server/FactoryGame/Mods/Example"))
+	testza.AssertNoError(t, d.Write("/server/FactoryGame/Mods/Example/Example.uplugin", []byte("{}")))
+	testza.AssertNoError(t, d.Write("/server/FactoryGame/Mods/Example/Binaries/Linux/lib Example (1).so", []byte("binary")))
+
+	w, err := d.Open("/server/FactoryGame/Mods/Example/Content/Paks/Example.pak", os.O_CREATE|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = io.WriteString(w, "streamed")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+	testza.AssertEqual(t, "streamed", string(fake.objects["server/FactoryGame/Mods/Example/Content/Paks/Example.pak"]))
+
+	exists, err = d.Exists("/server/FactoryGame/Mods")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	data, err := d.Read("/server/FactoryGame/Mods/Example/Binaries/Linux/lib Example (1).so")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "binary", string(data))
+
+	entries, err := d.ReadDir("/server/FactoryGame/Mods/Example")
+	testza.AssertNoError(t, err)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	testza.AssertLen(t, entries, 3)
+	testza.AssertEqual(t, "Binaries", entries[0].Name())
+	testza.AssertTrue(t, entries[0].IsDir())
+	testza.AssertEqual(t, "Content", entries[1].Name())
+	testza.AssertTrue(t, entries[1].IsDir())
+	testza.AssertEqual(t, "Example.uplugin", entries[2].Name())
+	testza.AssertFalse(t, entries[2].IsDir())
+	testza.AssertEqual(t, int64(2), entries[2].Size())
+
+	entry, err := d.Stat("/server/FactoryGame/Mods/Example/Content/Paks/Example.pak")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Example.pak", entry.Name())
+	testza.AssertEqual(t, int64(8), entry.Size())
+
+	entry, err = d.Stat("/server/FactoryGame/Mods/Example/Content")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, entry.IsDir())
+
+	_, err = d.Stat("/server/FactoryGame/Mods/Missing")
+	testza.AssertTrue(t, errors.Is(err, os.ErrNotExist))
+
+	_, err = d.Open("/server/FactoryGame/Mods/Example/Example.uplugin", os.O_CREATE|os.O_EXCL|os.O_WRONLY)
+	testza.AssertTrue(t, errors.Is(err, os.ErrExist))
+
+	w, err = d.Open("/server/FactoryGame/Mods/Example/Content/Paks/Example.pak", os.O_APPEND|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = io.WriteString(w, " appended")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+	testza.AssertEqual(t, "streamed appended", string(fake.objects["server/FactoryGame/Mods/Example/Content/Paks/Example.pak"]))
+
+	// Larger files are streamed in parts instead of being buffered completely
+	large := strings.Repeat("streamed in parts", 400<<10)
+	partSize := s3PartSize
+	s3PartSize = 5 << 20
+	w, err = d.Open("/server/FactoryGame/Mods/Example/Content/Paks/Large.pak", os.O_CREATE|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = io.WriteString(w, large)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+	s3PartSize = partSize
+	testza.AssertEqual(t, large, string(fake.objects["server/FactoryGame/Mods/Example/Content/Paks/Large.pak"]))
+	testza.AssertEqual(t, 2, fake.parts)
+	testza.AssertLen(t, fake.uploads, 0)
+	testza.AssertNoError(t, d.Remove("/server/FactoryGame/Mods/Example/Content/Paks/Large.pak"))
+
+	testza.AssertNoError(t, d.Rename("/server/FactoryGame/Mods/Example/Content", "/server/FactoryGame/Mods/Example/Moved"))
+	testza.AssertEqual(t, "streamed appended", string(fake.objects["server/FactoryGame/Mods/Example/Moved/Paks/Example.pak"]))
+	_, ok := fake.objects["server/FactoryGame/Mods/Example/Content/Paks/Example.pak"]
+	testza.AssertFalse(t, ok)
+
+	testza.AssertNoError(t, d.Rename("/server/FactoryGame/Mods/Example/Example.uplugin", "/server/FactoryGame/Mods/Example/Renamed.uplugin"))
+	testza.AssertEqual(t, "{}", string(fake.objects["server/FactoryGame/Mods/Example/Renamed.uplugin"]))
+
+	testza.AssertNoError(t, d.Remove("/server/FactoryGame/Mods/Example"))
+	testza.AssertLen(t, fake.objects, 0)
+
+	// Objects are deleted in batches instead of one request per object
+	testza.AssertEqual(t, 0, fake.deletes)
+
+	wrong, err := FromPath("s3://access:wrong@images/server?endpoint=" + s.URL)
+	testza.AssertNoError(t, err)
+	_, err = wrong.Read("/server/FactoryGame/Mods/Example/Example.uplugin")
+	testza.AssertNotNil(t, err)
+}
+
+func TestS3StoredCredential(t *testing.T) {
+	localDir := viper.GetString("local-dir")
+	passphrase := viper.GetString("credentials-passphrase")
+	credentialsFile := viper.GetString("credentials-file")
+	viper.Set("local-dir", t.TempDir())
+	viper.Set("credentials-file", "credentials.json")
+	viper.Set("credentials-passphrase", "")
+	t.Setenv(credentials.PassphraseEnv, "")
+	defer viper.Set("local-dir", localDir)
+	defer viper.Set("credentials-file", credentialsFile)
+	defer viper.Set("credentials-passphrase", passphrase)
+
+	s := startFakeS3(t)
+
+	// No passphrase in the environment, as when the TUI asked for it
+	_, err := credentials.Default()
+	testza.AssertTrue(t, errors.Is(err, credentials.ErrLocked))
+	testza.AssertNoError(t, credentials.Unlock("ficsit-test"))
+
+	u, err := url.Parse("s3://access:secret@images/server?endpoint=" + s.URL)
+	testza.AssertNoError(t, err)
+
+	extracted, id, credential, ok := credentials.Extract(u)
+	testza.AssertTrue(t, ok)
+
+	store, err := credentials.Default()
+	testza.AssertNoError(t, err)
+	store.Set(id, credential)
+	testza.AssertNoError(t, store.Save())
+
+	d, err := FromPath(extracted.String())
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, d.Write("/server/FactoryGame/Mods/Example/Example.uplugin", []byte("{}")))
+
+	data, err := d.Read("/server/FactoryGame/Mods/Example/Example.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "{}", string(data))
+}
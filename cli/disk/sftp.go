@@ -11,13 +11,20 @@ import (
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
 )
 
 var _ Disk = (*sftpDisk)(nil)
 
 type sftpDisk struct {
 	client *sftp.Client
+	ssh    *ssh.Client
+	tools  *remoteTools
 	path   string
+
+	// remoteExtract enables extracting archives on the server, set with extract=remote
+	remoteExtract bool
 }
 
 type sftpEntry struct {
@@ -38,21 +45,14 @@ func newSFTP(path string) (Disk, error) {
 		return nil, fmt.Errorf("failed to parse sftp url: %w", err)
 	}
 
-	password, ok := u.User.Password()
-	var auth []ssh.AuthMethod
-	if ok {
-		auth = append(auth, ssh.Password(password))
+	u, err = credentials.Resolve(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
 	}
 
-	conn, err := ssh.Dial("tcp", u.Host, &ssh.ClientConfig{
-		User: u.User.Username(),
-		Auth: auth,
-
-		// TODO Somehow use systems hosts file
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	})
+	conn, err := dialSSH(u)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to ssh server: %w", err)
+		return nil, err
 	}
 
 	client, err := sftp.NewClient(conn)
@@ -63,8 +63,11 @@ func newSFTP(path string) (Disk, error) {
 	slog.Info("logged into sftp")
 
 	return sftpDisk{
-		path:   path,
-		client: client,
+		path:          path,
+		client:        client,
+		ssh:           conn,
+		tools:         &remoteTools{},
+		remoteExtract: u.Query().Get("extract") == "remote",
 	}, nil
 }
 
@@ -121,6 +124,10 @@ func (l sftpDisk) Write(path string, data []byte) error {
 func (l sftpDisk) Remove(path string) error {
 	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 	if err := l.client.Remove(clean(path)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
 		if err := l.client.RemoveAll(clean(path)); err != nil {
 			return fmt.Errorf("failed to delete path: %w", err)
 		}
@@ -157,13 +164,66 @@ func (l sftpDisk) ReadDir(path string) ([]Entry, error) {
 	return entries, nil
 }
 
-func (l sftpDisk) Open(path string, _ int) (io.WriteCloser, error) {
+func (l sftpDisk) Open(path string, flag int) (io.WriteCloser, error) {
 	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
-	f, err := l.client.Create(clean(path))
+	f, err := l.client.OpenFile(clean(path), flag)
 	if err != nil {
-		slog.Error("failed to open file", slog.Any("err", err))
+		// SFTP v3 has no status for existing files, the server reports a generic failure instead
+		if flag&os.O_EXCL != 0 && !errors.Is(err, os.ErrNotExist) {
+			if _, statErr := l.client.Stat(clean(path)); statErr == nil {
+				return nil, fmt.Errorf("failed to open file: %w", os.ErrExist)
+			}
+		}
+
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if flag&os.O_APPEND != 0 {
+		// Not every server honors the append flag, so start writing at the end explicitly
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to seek to end of file: %w", err)
+		}
 	}
 
 	return f, nil
 }
+
+func (l sftpDisk) Stat(path string) (Entry, error) {
+	slog.Debug("getting file info", slog.String("path", clean(path)), slog.String("schema", "sftp"))
+
+	info, err := l.client.Stat(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	return sftpEntry{
+		FileInfo: info,
+	}, nil
+}
+
+func (l sftpDisk) Rename(from string, to string) error {
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "sftp"))
+
+	if _, ok := l.client.HasExtension("posix-rename@openssh.com"); ok {
+		if err := l.client.PosixRename(clean(from), clean(to)); err != nil {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+
+		return nil
+	}
+
+	// Plain SFTP rename fails if the target exists
+	if info, err := l.client.Stat(clean(to)); err == nil && !info.IsDir() {
+		if err := l.client.Remove(clean(to)); err != nil {
+			return fmt.Errorf("failed to replace target: %w", err)
+		}
+	}
+
+	if err := l.client.Rename(clean(from), clean(to)); err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package disk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+)
+
+var _ ArchiveExtractor = (*sftpDisk)(nil)
+
+// remoteTools caches whether the server has the tools needed for remote extraction
+type remoteTools struct {
+	err  error
+	once sync.Once
+}
+
+func (l sftpDisk) ExtractArchive(archive io.Reader, target string) error {
+	if !l.remoteExtract {
+		return ErrRemoteExtractUnsupported
+	}
+
+	l.tools.once.Do(func() {
+		_, l.tools.err = l.exec("command -v unzip && command -v sha256sum")
+	})
+
+	if l.tools.err != nil {
+		slog.Info("remote extraction tools unavailable, falling back to per-file upload", slog.Any("err", l.tools.err))
+		return ErrRemoteExtractUnsupported
+	}
+
+	target = clean(target)
+	archivePath := path.Join(path.Dir(target), "."+path.Base(target)+".ficsit.zip")
+
+	slog.Debug("uploading archive", slog.String("path", archivePath), slog.String("schema", "sftp"))
+
+	f, err := l.client.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create remote archive: %w", err)
+	}
+
+	defer func() {
+		if err := l.client.Remove(archivePath); err != nil {
+			slog.Warn("failed to remove remote archive", slog.String("path", archivePath), slog.Any("err", err))
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(f, io.TeeReader(archive, hash)); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	output, err := l.exec("sha256sum " + shellQuote(archivePath))
+	if err != nil {
+		return fmt.Errorf("failed to hash remote archive: %w", err)
+	}
+
+	localHash := hex.EncodeToString(hash.Sum(nil))
+	if fields := strings.Fields(output); len(fields) == 0 || !strings.EqualFold(fields[0], localHash) {
+		return fmt.Errorf("remote archive hash mismatch, expected %s got %s", localHash, strings.TrimSpace(output))
+	}
+
+	slog.Debug("extracting archive remotely", slog.String("path", archivePath), slog.String("target", target), slog.String("schema", "sftp"))
+
+	if _, err := l.exec("unzip -o -q " + shellQuote(archivePath) + " -d " + shellQuote(target)); err != nil {
+		return fmt.Errorf("failed to extract archive remotely: %w", err)
+	}
+
+	return nil
+}
+
+// exec runs the command on the server and returns its standard output
+func (l sftpDisk) exec(command string) (string, error) {
+	session, err := l.ssh.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to open ssh session: %w", err)
+	}
+
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	if err := session.Run(command); err != nil {
+		return stdout.String(), fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// shellQuote quotes the value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
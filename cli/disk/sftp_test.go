@@ -0,0 +1,242 @@
+package disk
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/pkg/sftp"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+)
+
+// startSFTPServer starts an in-process SSH server with the sftp subsystem, accepting user/pass.
+// When allowExec is false, exec requests fail as if no tools were installed.
+func startSFTPServer(t *testing.T, allowExec bool) string {
+	t.Helper()
+
+	knownHostsFile, acceptHostKey := viper.GetString("known-hosts-file"), viper.GetBool("accept-host-key")
+	t.Cleanup(func() {
+		viper.Set("known-hosts-file", knownHostsFile)
+		viper.Set("accept-host-key", acceptHostKey)
+	})
+
+	viper.Set("known-hosts-file", filepath.Join(t.TempDir(), "known_hosts"))
+	viper.Set("accept-host-key", true)
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	testza.AssertNoError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	testza.AssertNoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "user" && string(password) == "pass" {
+				return nil, nil
+			}
+			return nil, errors.New("invalid credentials")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testza.AssertNoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config, allowExec)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig, allowExec bool) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			for req := range channelRequests {
+				switch req.Type {
+				case "subsystem":
+					_ = req.Reply(true, nil)
+
+					server, err := sftp.NewServer(channel)
+					if err != nil {
+						_ = channel.Close()
+						return
+					}
+
+					_ = server.Serve()
+					_ = channel.Close()
+					return
+				case "exec":
+					_ = req.Reply(true, nil)
+
+					var payload struct {
+						Command string
+					}
+					_ = ssh.Unmarshal(req.Payload, &payload)
+
+					status := uint32(127)
+					if allowExec {
+						cmd := exec.Command("sh", "-c", payload.Command)
+						cmd.Stdout = channel
+						cmd.Stderr = channel.Stderr()
+						status = 0
+						if err := cmd.Run(); err != nil {
+							status = 1
+							var exitErr *exec.ExitError
+							if errors.As(err, &exitErr) {
+								status = uint32(exitErr.ExitCode())
+							}
+						}
+					}
+
+					_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+					_ = channel.Close()
+					return
+				default:
+					_ = req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func testArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	w := zip.NewWriter(&buffer)
+
+	for name, content := range map[string]string{
+		"Example.uplugin":                    "{}",
+		"Content/Paks/LinuxServer/Pak.pak":   "pak",
+		"Binaries/LinuxServer/libExample.so": "lib",
+	} {
+		f, err := w.Create(name)
+		testza.AssertNoError(t, err)
+		_, err = f.Write([]byte(content))
+		testza.AssertNoError(t, err)
+	}
+
+	testza.AssertNoError(t, w.Close())
+
+	return buffer.Bytes()
+}
+
+func TestSFTPRemoteExtract(t *testing.T) {
+	if _, err := exec.LookPath("unzip"); err != nil {
+		t.Skip("unzip is not installed")
+	}
+
+	root := t.TempDir()
+	address := startSFTPServer(t, true)
+
+	d, err := FromPath(fmt.Sprintf("sftp://user:pass@%s%s?extract=remote", address, filepath.ToSlash(root)))
+	testza.AssertNoError(t, err)
+
+	target := filepath.Join(root, "FactoryGame", "Mods", "Example")
+	testza.AssertNoError(t, d.MkDir(target))
+	testza.AssertNoError(t, d.(ArchiveExtractor).ExtractArchive(bytes.NewReader(testArchive(t)), target))
+
+	data, err := os.ReadFile(filepath.Join(target, "Content", "Paks", "LinuxServer", "Pak.pak"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "pak", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(target))
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, entries, 1, "uploaded archive should be removed")
+}
+
+func TestSFTPRemoteExtractFallback(t *testing.T) {
+	root := t.TempDir()
+
+	// Without tools on the server
+	address := startSFTPServer(t, false)
+	d, err := FromPath(fmt.Sprintf("sftp://user:pass@%s%s?extract=remote", address, filepath.ToSlash(root)))
+	testza.AssertNoError(t, err)
+
+	err = d.(ArchiveExtractor).ExtractArchive(bytes.NewReader(testArchive(t)), filepath.Join(root, "Example"))
+	testza.AssertTrue(t, errors.Is(err, ErrRemoteExtractUnsupported))
+
+	// Without opting in
+	d, err = FromPath(fmt.Sprintf("sftp://user:pass@%s%s", address, filepath.ToSlash(root)))
+	testza.AssertNoError(t, err)
+
+	err = d.(ArchiveExtractor).ExtractArchive(bytes.NewReader(testArchive(t)), filepath.Join(root, "Example"))
+	testza.AssertTrue(t, errors.Is(err, ErrRemoteExtractUnsupported))
+
+	entries, err := os.ReadDir(root)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, entries, 0)
+}
+
+func TestSFTPStatRename(t *testing.T) {
+	root := t.TempDir()
+	address := startSFTPServer(t, false)
+
+	d, err := FromPath(fmt.Sprintf("sftp://user:pass@%s%s", address, filepath.ToSlash(root)))
+	testza.AssertNoError(t, err)
+
+	target := filepath.Join(root, "test.txt")
+	testza.AssertNoError(t, d.Write(target, []byte("hello")))
+
+	entry, err := d.Stat(target)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, int64(5), entry.Size())
+	testza.AssertFalse(t, entry.ModTime().IsZero())
+
+	_, err = d.Stat(filepath.Join(root, "missing.txt"))
+	testza.AssertTrue(t, errors.Is(err, os.ErrNotExist))
+
+	_, err = d.Open(filepath.Join(root, "missing.txt"), os.O_WRONLY)
+	testza.AssertNotNil(t, err)
+
+	w, err := d.Open(target, os.O_APPEND|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = w.Write([]byte(" world"))
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(root, "other.txt"), []byte("other"), 0o600))
+	testza.AssertNoError(t, d.Rename(target, filepath.Join(root, "other.txt")))
+
+	data, err := os.ReadFile(filepath.Join(root, "other.txt"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hello world", string(data))
+}
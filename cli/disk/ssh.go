@@ -0,0 +1,444 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Prompter asks the user for a secret (or any other answer) during authentication.
+// echo is false when the answer should not be shown while typing.
+type Prompter func(prompt string, echo bool) (string, error)
+
+var (
+	prompter      Prompter
+	knownHostsMux sync.Mutex
+)
+
+// SetPrompter sets the prompter used to ask for key passphrases,
+// passwords and keyboard-interactive answers.
+//
+// Without a prompter, authentication methods that need user input are skipped.
+func SetPrompter(p Prompter) {
+	prompter = p
+}
+
+// UnknownHostKeyError is returned when connecting to a host that is not present in the known hosts file
+type UnknownHostKeyError struct {
+	Key      ssh.PublicKey
+	Hostname string
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("unknown host key for %s (%s %s), verify it and re-run with --accept-host-key to trust it", e.Hostname, e.Key.Type(), e.Fingerprint())
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key
+func (e *UnknownHostKeyError) Fingerprint() string {
+	return ssh.FingerprintSHA256(e.Key)
+}
+
+// KnownHostsFile returns the path to the known hosts file used to verify SSH hosts
+func KnownHostsFile() string {
+	if file := viper.GetString("known-hosts-file"); file != "" {
+		return file
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".ssh", "known_hosts")
+	}
+
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// TrustHostKey appends the provided host key to the known hosts file
+func TrustHostKey(hostname string, key ssh.PublicKey) error {
+	knownHostsMux.Lock()
+	defer knownHostsMux.Unlock()
+
+	file := KnownHostsFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("failed to create known hosts directory: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open known hosts file: %w", err)
+	}
+
+	defer f.Close()
+
+	if _, err := f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n"); err != nil {
+		return fmt.Errorf("failed to write known hosts file: %w", err)
+	}
+
+	slog.Info("trusted host key", slog.String("host", hostname), slog.String("fingerprint", ssh.FingerprintSHA256(key)))
+
+	return nil
+}
+
+// dialSSH connects to the host in the provided url, optionally through
+// the jump hosts listed in the "jump" query parameter
+func dialSSH(u *url.URL) (*ssh.Client, error) {
+	var client *ssh.Client
+
+	hops := make([]*url.URL, 0)
+	if jump := u.Query().Get("jump"); jump != "" {
+		for _, hop := range strings.Split(jump, ",") {
+			hopURL, err := url.Parse("ssh://" + strings.TrimSpace(hop))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse jump host %s: %w", hop, err)
+			}
+
+			hops = append(hops, hopURL)
+		}
+	}
+
+	hops = append(hops, u)
+
+	agentSocket := os.Getenv("SSH_AUTH_SOCK")
+
+	for _, hop := range hops {
+		address := hostWithPort(hop.Host)
+
+		var hopAgent *sshAgent
+		if agentSocket != "" {
+			hopAgent = &sshAgent{socket: agentSocket}
+		}
+
+		config, err := clientConfig(hop, address, hopAgent)
+		if err != nil {
+			if client != nil {
+				_ = client.Close()
+			}
+			return nil, err
+		}
+
+		if client == nil {
+			client, err = ssh.Dial("tcp", address, config)
+			if err != nil {
+				_ = hopAgent.Close()
+				return nil, fmt.Errorf("failed to connect to ssh server %s: %w", address, err)
+			}
+
+			closeWithClient(client, hopAgent)
+
+			continue
+		}
+
+		conn, err := client.Dial("tcp", address)
+		if err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to connect to %s through jump host: %w", address, err)
+		}
+
+		clientConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+		if err != nil {
+			_ = hopAgent.Close()
+			_ = conn.Close()
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to connect to ssh server %s: %w", address, err)
+		}
+
+		jumpClient := client
+		client = ssh.NewClient(clientConn, chans, reqs)
+
+		closeWithClient(client, hopAgent, jumpClient)
+	}
+
+	return client, nil
+}
+
+// closeWithClient closes the resources a client depends on once its connection is closed
+func closeWithClient(client *ssh.Client, closers ...io.Closer) {
+	go func() {
+		_ = client.Wait()
+
+		for _, closer := range closers {
+			if closer != nil {
+				_ = closer.Close()
+			}
+		}
+	}()
+}
+
+// sshAgent connects to the ssh agent when keys are first requested.
+//
+// The agent signs the authentication challenges over the connection, so it is kept open until closed.
+type sshAgent struct {
+	conn   net.Conn
+	socket string
+	mux    sync.Mutex
+}
+
+func (a *sshAgent) signers() ([]ssh.Signer, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if a.conn == nil {
+		conn, err := net.Dial("unix", a.socket)
+		if err != nil {
+			slog.Debug("failed to connect to ssh agent", slog.Any("err", err))
+			return nil, nil
+		}
+
+		a.conn = conn
+	}
+
+	signers, err := agent.NewClient(a.conn).Signers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ssh agent keys: %w", err)
+	}
+
+	return signers, nil
+}
+
+func (a *sshAgent) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if a.conn == nil {
+		return nil
+	}
+
+	err := a.conn.Close()
+	a.conn = nil
+
+	return err //nolint:wrapcheck
+}
+
+func hostWithPort(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	return net.JoinHostPort(strings.Trim(host, "[]"), "22")
+}
+
+func clientConfig(u *url.URL, address string, keyAgent *sshAgent) (*ssh.ClientConfig, error) {
+	knownHosts, err := loadKnownHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	username := u.User.Username()
+	if username == "" {
+		username = os.Getenv("USER")
+	}
+
+	return &ssh.ClientConfig{
+		User:              username,
+		Auth:              authMethods(u, keyAgent),
+		HostKeyCallback:   verifyHostKey(knownHosts),
+		HostKeyAlgorithms: knownHostKeyAlgorithms(knownHosts, address),
+	}, nil
+}
+
+func authMethods(u *url.URL, keyAgent *sshAgent) []ssh.AuthMethod {
+	auth := make([]ssh.AuthMethod, 0)
+
+	if signers := keySigners(u); len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+
+	if keyAgent != nil {
+		auth = append(auth, ssh.PublicKeysCallback(keyAgent.signers))
+	}
+
+	password, hasPassword := u.User.Password()
+
+	auth = append(auth, ssh.KeyboardInteractive(func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i, question := range questions {
+			if hasPassword && !echos[i] {
+				answers[i] = password
+				continue
+			}
+
+			if prompter == nil {
+				return nil, errors.New("keyboard-interactive authentication requires a terminal")
+			}
+
+			answer, err := prompter(strings.TrimSpace(instruction+" "+question), echos[i])
+			if err != nil {
+				return nil, err
+			}
+
+			answers[i] = answer
+		}
+
+		return answers, nil
+	}))
+
+	if hasPassword {
+		auth = append(auth, ssh.Password(password))
+	} else if prompter != nil {
+		auth = append(auth, ssh.PasswordCallback(func() (string, error) {
+			return prompter(fmt.Sprintf("%s@%s's password: ", u.User.Username(), u.Hostname()), false)
+		}))
+	}
+
+	return auth
+}
+
+// keySigners loads the private keys listed in the "key" query parameters,
+// falling back to the default keys in ~/.ssh
+func keySigners(u *url.URL) []ssh.Signer {
+	files := u.Query()["key"]
+	explicit := len(files) > 0
+
+	if !explicit {
+		if home, err := os.UserHomeDir(); err == nil {
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				files = append(files, filepath.Join(home, ".ssh", name))
+			}
+		}
+	}
+
+	signers := make([]ssh.Signer, 0)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if explicit || !os.IsNotExist(err) {
+				slog.Warn("failed to read ssh key", slog.String("file", file), slog.Any("err", err))
+			}
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			var missing *ssh.PassphraseMissingError
+			if !errors.As(err, &missing) {
+				slog.Warn("failed to parse ssh key", slog.String("file", file), slog.Any("err", err))
+				continue
+			}
+
+			if prompter == nil {
+				slog.Warn("skipping encrypted ssh key, no terminal to ask for passphrase", slog.String("file", file))
+				continue
+			}
+
+			passphrase, err := prompter(fmt.Sprintf("Enter passphrase for key '%s': ", file), false)
+			if err != nil {
+				slog.Warn("failed to read ssh key passphrase", slog.String("file", file), slog.Any("err", err))
+				continue
+			}
+
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+			if err != nil {
+				slog.Warn("failed to decrypt ssh key", slog.String("file", file), slog.Any("err", err))
+				continue
+			}
+		}
+
+		signers = append(signers, signer)
+	}
+
+	return signers
+}
+
+func loadKnownHosts() (ssh.HostKeyCallback, error) {
+	file := KnownHostsFile()
+
+	knownHostsMux.Lock()
+	defer knownHostsMux.Unlock()
+
+	files := make([]string, 0)
+	if _, err := os.Stat(file); err == nil {
+		files = append(files, file)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat known hosts file: %w", err)
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts file: %w", err)
+	}
+
+	return callback, nil
+}
+
+// verifyHostKey wraps the known hosts callback to trust unknown hosts
+// when --accept-host-key is set, or return an UnknownHostKeyError otherwise
+func verifyHostKey(knownHosts ssh.HostKeyCallback) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := knownHosts(hostname, remote, key)
+		if err == nil {
+			return nil
+		}
+
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return fmt.Errorf("failed to verify host key: %w", err)
+		}
+
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("host key mismatch for %s (%s %s), the server may have been reinstalled or someone may be intercepting the connection: %w", hostname, key.Type(), ssh.FingerprintSHA256(key), err)
+		}
+
+		if viper.GetBool("accept-host-key") {
+			return TrustHostKey(hostname, key)
+		}
+
+		return &UnknownHostKeyError{
+			Hostname: hostname,
+			Key:      key,
+		}
+	}
+}
+
+// knownHostKeyAlgorithms returns the host key algorithms of keys already
+// trusted for the address, so that the server does not offer a different
+// key type which would be treated as a mismatch
+func knownHostKeyAlgorithms(knownHosts ssh.HostKeyCallback, address string) []string {
+	err := knownHosts(address, &net.TCPAddr{IP: net.IPv4zero}, probeKey{})
+
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) || len(keyErr.Want) == 0 {
+		return nil
+	}
+
+	algorithms := make([]string, 0)
+	for _, known := range keyErr.Want {
+		if known.Key.Type() == ssh.KeyAlgoRSA {
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algorithms = append(algorithms, known.Key.Type())
+	}
+
+	return algorithms
+}
+
+// probeKey is never present in a known hosts file
+type probeKey struct{}
+
+func (probeKey) Type() string {
+	return "probe"
+}
+
+func (probeKey) Marshal() []byte {
+	return []byte("probe")
+}
+
+func (probeKey) Verify([]byte, *ssh.Signature) error {
+	return errors.New("probe key can not verify signatures")
+}
+
+var _ ssh.PublicKey = probeKey{}
@@ -0,0 +1,172 @@
+package disk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	testza.AssertNoError(t, err)
+
+	key, err := ssh.NewPublicKey(pub)
+	testza.AssertNoError(t, err)
+
+	return key
+}
+
+func TestHostKeyVerification(t *testing.T) {
+	knownHostsFile, acceptHostKey := viper.GetString("known-hosts-file"), viper.GetBool("accept-host-key")
+	defer func() {
+		viper.Set("known-hosts-file", knownHostsFile)
+		viper.Set("accept-host-key", acceptHostKey)
+	}()
+
+	viper.Set("known-hosts-file", filepath.Join(t.TempDir(), "known_hosts"))
+	viper.Set("accept-host-key", false)
+
+	address := "example.com:2222"
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 2222}
+	key := newHostKey(t)
+
+	knownHosts, err := loadKnownHosts()
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, knownHostKeyAlgorithms(knownHosts, address))
+
+	err = verifyHostKey(knownHosts)(address, remote, key)
+	var hostKeyErr *UnknownHostKeyError
+	testza.AssertTrue(t, errors.As(err, &hostKeyErr))
+	testza.AssertEqual(t, ssh.FingerprintSHA256(key), hostKeyErr.Fingerprint())
+
+	viper.Set("accept-host-key", true)
+	err = verifyHostKey(knownHosts)(address, remote, key)
+	viper.Set("accept-host-key", false)
+	testza.AssertNoError(t, err)
+
+	knownHosts, err = loadKnownHosts()
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, verifyHostKey(knownHosts)(address, remote, key))
+	testza.AssertEqual(t, []string{ssh.KeyAlgoED25519}, knownHostKeyAlgorithms(knownHosts, address))
+
+	err = verifyHostKey(knownHosts)(address, remote, newHostKey(t))
+	testza.AssertNotNil(t, err)
+	testza.AssertFalse(t, errors.As(err, &hostKeyErr))
+}
+
+func TestHostWithPort(t *testing.T) {
+	testza.AssertEqual(t, "example.com:22", hostWithPort("example.com"))
+	testza.AssertEqual(t, "example.com:2222", hostWithPort("example.com:2222"))
+	testza.AssertEqual(t, "[::1]:22", hostWithPort("[::1]"))
+}
+
+func TestSSHAgentConnectionClosed(t *testing.T) {
+	knownHostsFile, acceptHostKey := viper.GetString("known-hosts-file"), viper.GetBool("accept-host-key")
+	defer func() {
+		viper.Set("known-hosts-file", knownHostsFile)
+		viper.Set("accept-host-key", acceptHostKey)
+	}()
+
+	viper.Set("known-hosts-file", filepath.Join(t.TempDir(), "known_hosts"))
+	viper.Set("accept-host-key", true)
+
+	_, userKey, err := ed25519.GenerateKey(rand.Reader)
+	testza.AssertNoError(t, err)
+
+	keyring := agent.NewKeyring()
+	testza.AssertNoError(t, keyring.Add(agent.AddedKey{PrivateKey: userKey}))
+
+	userSigner, err := ssh.NewSignerFromKey(userKey)
+	testza.AssertNoError(t, err)
+
+	// Unix socket paths are limited in length, so the default test directory may be too long
+	socketDir, err := os.MkdirTemp("", "agent")
+	testza.AssertNoError(t, err)
+	defer os.RemoveAll(socketDir)
+
+	agentListener, err := net.Listen("unix", filepath.Join(socketDir, "agent.sock"))
+	testza.AssertNoError(t, err)
+	defer agentListener.Close()
+
+	agentClosed := make(chan struct{}, 10)
+	go func() {
+		for {
+			conn, err := agentListener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				_ = agent.ServeAgent(keyring, conn)
+				agentClosed <- struct{}{}
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", agentListener.Addr().String())
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	testza.AssertNoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	testza.AssertNoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), userSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testza.AssertNoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config, false)
+		}
+	}()
+
+	u, err := url.Parse("sftp://user@" + listener.Addr().String() + "/")
+	testza.AssertNoError(t, err)
+
+	client, err := dialSSH(u)
+	testza.AssertNoError(t, err)
+
+	select {
+	case <-agentClosed:
+		t.Fatal("the agent connection was closed while the client is open")
+	default:
+	}
+
+	testza.AssertNoError(t, client.Close())
+
+	select {
+	case <-agentClosed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the agent connection was not closed with the client")
+	}
+}
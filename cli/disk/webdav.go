@@ -0,0 +1,441 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+var _ Disk = (*webdavDisk)(nil)
+
+type webdavDisk struct {
+	client   *http.Client
+	base     *url.URL
+	username string
+	password string
+}
+
+type webdavEntry struct {
+	modTime time.Time
+	name    string
+	size    int64
+	isDir   bool
+}
+
+func (e webdavEntry) IsDir() bool {
+	return e.isDir
+}
+
+func (e webdavEntry) Name() string {
+	return e.name
+}
+
+func (e webdavEntry) Size() int64 {
+	return e.size
+}
+
+func (e webdavEntry) ModTime() time.Time {
+	return e.modTime
+}
+
+func (e webdavEntry) Mode() fs.FileMode {
+	return entryMode(e.isDir)
+}
+
+// webdav:// uses plain http, webdavs:// uses https.
+// Certificate verification can be disabled for self-signed hosts with tls-insecure=true.
+func newWebDAV(p string) (Disk, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webdav url: %w", err)
+	}
+
+	u, err = credentials.Resolve(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+
+	scheme := "http"
+	if u.Scheme == "webdavs" {
+		scheme = "https"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure, _ := strconv.ParseBool(u.Query().Get("tls-insecure")); insecure {
+		slog.Warn("webdav tls certificate verification is disabled", slog.String("host", u.Hostname()))
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	password, _ := u.User.Password()
+
+	return webdavDisk{
+		client: &http.Client{
+			Transport: transport,
+		},
+		base: &url.URL{
+			Scheme: scheme,
+			Host:   u.Host,
+			Path:   clean(u.Path),
+		},
+		username: u.User.Username(),
+		password: password,
+	}, nil
+}
+
+func (l webdavDisk) request(method string, p string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	target := *l.base
+	target.Path = clean(p)
+
+	req, err := http.NewRequestWithContext(context.TODO(), method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if l.username != "" || l.password != "" {
+		req.SetBasicAuth(l.username, l.password)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, target.Path, err)
+	}
+
+	return resp, nil
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (l webdavDisk) Exists(p string) (bool, error) {
+	slog.Debug("checking if file exists", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	resp, err := l.request("PROPFIND", p, nil, map[string]string{"Depth": "0"})
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusMultiStatus, http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("failed to check if file exists: %w", statusError(resp))
+}
+
+func (l webdavDisk) Read(p string) ([]byte, error) {
+	slog.Debug("reading file", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	resp, err := l.request(http.MethodGet, p, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("failed to retrieve path: %w", os.ErrNotExist)
+	default:
+		return nil, fmt.Errorf("failed to retrieve path: %w", statusError(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (l webdavDisk) Write(p string, data []byte) error {
+	slog.Debug("writing to file", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	return l.put(p, bytes.NewReader(data), nil)
+}
+
+func (l webdavDisk) put(p string, body io.Reader, headers map[string]string) error {
+	resp, err := l.request(http.MethodPut, p, body, headers)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("failed to write file: %w", os.ErrExist)
+	}
+
+	return fmt.Errorf("failed to write file: %w", statusError(resp))
+}
+
+func (l webdavDisk) Remove(p string) error {
+	slog.Debug("deleting path", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	// DELETE on a collection is always recursive
+	resp, err := l.request(http.MethodDelete, p, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+
+	return fmt.Errorf("failed to delete path: %w", statusError(resp))
+}
+
+func (l webdavDisk) MkDir(p string) error {
+	slog.Debug("making directory", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	// Walk up to the closest existing directory, servers mounted under a prefix reject requests above the url path
+	missing := make([]string, 0)
+	for current := clean(p); !l.aboveBase(current); current = path.Dir(current) {
+		exists, err := l.Exists(current)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			break
+		}
+
+		missing = append(missing, current)
+	}
+
+	for idx := len(missing) - 1; idx >= 0; idx-- {
+		resp, err := l.request("MKCOL", missing[idx], nil, nil)
+		if err != nil {
+			return err
+		}
+
+		_ = resp.Body.Close()
+
+		// 405 means the collection already exists
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("failed to make directory %s: %w", missing[idx], statusError(resp))
+		}
+	}
+
+	return nil
+}
+
+// aboveBase returns true if the path is the server root or an ancestor of the url path the disk was opened with
+func (l webdavDisk) aboveBase(p string) bool {
+	if p == "/" || p == "." {
+		return true
+	}
+
+	base := strings.TrimSuffix(l.base.Path, "/")
+	return p != base && strings.HasPrefix(base+"/", strings.TrimSuffix(p, "/")+"/")
+}
+
+type multiStatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				LastModified  string `xml:"getlastmodified"`
+				ContentLength int64  `xml:"getcontentlength"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const propfindEntry = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/><getlastmodified/></prop></propfind>`
+
+// propfind returns the entries of the multistatus response for the provided path.
+// The path itself is returned under the name ".".
+func (l webdavDisk) propfind(p string, depth string) ([]webdavEntry, error) {
+	resp, err := l.request("PROPFIND", p, strings.NewReader(propfindEntry), map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+	}
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("failed to get path info: %w", statusError(resp))
+	}
+
+	var status multiStatus
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to parse path info: %w", err)
+	}
+
+	self := strings.TrimSuffix(clean(p), "/")
+
+	entries := make([]webdavEntry, 0, len(status.Responses))
+	for _, response := range status.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse href %s: %w", response.Href, err)
+		}
+
+		entryPath := strings.TrimSuffix(path.Clean(href.Path), "/")
+
+		entry := webdavEntry{
+			name: path.Base(entryPath),
+		}
+
+		if entryPath == self || entryPath == "" {
+			entry.name = "."
+		}
+
+		for _, propstat := range response.Propstat {
+			if propstat.Prop.ResourceType.Collection != nil {
+				entry.isDir = true
+			}
+
+			if propstat.Prop.ContentLength > 0 {
+				entry.size = propstat.Prop.ContentLength
+			}
+
+			if modTime, err := http.ParseTime(propstat.Prop.LastModified); err == nil {
+				entry.modTime = modTime
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (l webdavDisk) ReadDir(p string) ([]Entry, error) {
+	slog.Debug("reading directory", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	found, err := l.propfind(p, "1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(found))
+	for _, entry := range found {
+		if entry.name == "." {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (l webdavDisk) Stat(p string) (Entry, error) {
+	slog.Debug("getting file info", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	found, err := l.propfind(p, "0")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range found {
+		if entry.name == "." {
+			entry.name = path.Base(clean(p))
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get path info: %w", os.ErrNotExist)
+}
+
+func (l webdavDisk) Rename(from string, to string) error {
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "webdav"))
+
+	destination := *l.base
+	destination.Path = clean(to)
+
+	resp, err := l.request("MOVE", from, nil, map[string]string{
+		"Destination": destination.String(),
+		"Overwrite":   "T",
+	})
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("failed to rename path: %w", os.ErrNotExist)
+	}
+
+	return fmt.Errorf("failed to rename path: %w", statusError(resp))
+}
+
+func (l webdavDisk) Open(p string, flag int) (io.WriteCloser, error) {
+	if err := checkOpenFlags(l, p, flag); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("opening for writing", slog.String("path", clean(p)), slog.String("schema", "webdav"))
+
+	var existing []byte
+	if flag&os.O_APPEND != 0 {
+		// PUT always replaces the whole file, so re-upload the current contents first
+		exists, err := l.Exists(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			existing, err = l.Read(p)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The existence check above is not atomic, servers supporting If-None-Match close the gap
+	var headers map[string]string
+	if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+		headers = map[string]string{"If-None-Match": "*"}
+	}
+
+	return newAsyncWriter(func(reader io.Reader) error {
+		return l.put(p, io.MultiReader(bytes.NewReader(existing), reader), headers)
+	}), nil
+}
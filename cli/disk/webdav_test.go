@@ -0,0 +1,190 @@
+package disk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"golang.org/x/net/webdav"
+)
+
+func startWebDAVServer(t *testing.T, root string) *url.URL {
+	t.Helper()
+
+	handler := &webdav.Handler{
+		FileSystem: webdav.Dir(root),
+		LockSystem: webdav.NewMemLS(),
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// x/net/webdav ignores If-None-Match, while real servers refuse to replace existing files with it.
+		// The body is read first, so files created while uploading are detected too.
+		if r.Method == http.MethodPut && r.Header.Get("If-None-Match") == "*" {
+			body, err := io.ReadAll(r.Body)
+			testza.AssertNoError(t, err)
+
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(r.URL.Path))); err == nil {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	u, err := url.Parse(s.URL)
+	testza.AssertNoError(t, err)
+
+	return u
+}
+
+func TestWebDAV(t *testing.T) {
+	root := t.TempDir()
+	server := startWebDAVServer(t, root)
+
+	d, err := FromPath("webdav://user:pass@" + server.Host + "/server")
+	testza.AssertNoError(t, err)
+
+	exists, err := d.Exists("/server/FactoryGame/Mods")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	testza.AssertNoError(t, d.MkDir("/server/FactoryGame/Mods/Example"))
+	testza.AssertNoError(t, d.MkDir("/server/FactoryGame/Mods/Example"))
+	testza.AssertNoError(t, d.Write("/server/FactoryGame/Mods/Example/Example.uplugin", []byte("{}")))
+
+	w, err := d.Open("/server/FactoryGame/Mods/Example/large file.pak", os.O_CREATE|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = io.WriteString(w, "streamed")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+
+	data, err := os.ReadFile(filepath.Join(root, "server", "FactoryGame", "Mods", "Example", "large file.pak"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "streamed", string(data))
+
+	data, err = d.Read("/server/FactoryGame/Mods/Example/Example.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "{}", string(data))
+
+	testza.AssertNoError(t, d.MkDir("/server/FactoryGame/Mods/Example/Binaries"))
+
+	entries, err := d.ReadDir("/server/FactoryGame/Mods/Example")
+	testza.AssertNoError(t, err)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	testza.AssertLen(t, entries, 3)
+	testza.AssertEqual(t, "Binaries", entries[0].Name())
+	testza.AssertTrue(t, entries[0].IsDir())
+	testza.AssertEqual(t, "Example.uplugin", entries[1].Name())
+	testza.AssertFalse(t, entries[1].IsDir())
+	testza.AssertEqual(t, "large file.pak", entries[2].Name())
+	testza.AssertEqual(t, int64(8), entries[2].Size())
+
+	entry, err := d.Stat("/server/FactoryGame/Mods/Example/Example.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Example.uplugin", entry.Name())
+	testza.AssertEqual(t, int64(2), entry.Size())
+	testza.AssertFalse(t, entry.ModTime().IsZero())
+
+	entry, err = d.Stat("/server/FactoryGame/Mods/Example/Binaries")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, entry.IsDir())
+
+	_, err = d.Stat("/server/FactoryGame/Mods/Example/Missing")
+	testza.AssertTrue(t, errors.Is(err, os.ErrNotExist))
+
+	_, err = d.Open("/server/FactoryGame/Mods/Example/Example.uplugin", os.O_CREATE|os.O_EXCL|os.O_WRONLY)
+	testza.AssertTrue(t, errors.Is(err, os.ErrExist))
+
+	// Exclusive creation also fails if the file appears after the existence check
+	w, err = d.Open("/server/FactoryGame/Mods/Example/Raced.txt", os.O_CREATE|os.O_EXCL|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(root, "server", "FactoryGame", "Mods", "Example", "Raced.txt"), []byte("other"), 0o644))
+	_, err = io.WriteString(w, "mine")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, errors.Is(w.Close(), os.ErrExist))
+
+	data, err = os.ReadFile(filepath.Join(root, "server", "FactoryGame", "Mods", "Example", "Raced.txt"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "other", string(data))
+	testza.AssertNoError(t, d.Remove("/server/FactoryGame/Mods/Example/Raced.txt"))
+
+	w, err = d.Open("/server/FactoryGame/Mods/Example/large file.pak", os.O_APPEND|os.O_WRONLY)
+	testza.AssertNoError(t, err)
+	_, err = io.WriteString(w, " appended")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+
+	testza.AssertNoError(t, d.Rename("/server/FactoryGame/Mods/Example/large file.pak", "/server/FactoryGame/Mods/Example/Example.uplugin"))
+
+	data, err = d.Read("/server/FactoryGame/Mods/Example/Example.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "streamed appended", string(data))
+
+	testza.AssertNoError(t, d.Remove("/server/FactoryGame/Mods/Example"))
+	testza.AssertNoError(t, d.Remove("/server/FactoryGame/Mods/Example"))
+
+	exists, err = d.Exists("/server/FactoryGame/Mods/Example")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	unauthorized, err := FromPath("webdav://user:wrong@" + server.Host + "/server")
+	testza.AssertNoError(t, err)
+
+	_, err = unauthorized.Exists("/server")
+	testza.AssertNotNil(t, err)
+}
+
+func TestWebDAVMountPrefix(t *testing.T) {
+	root := t.TempDir()
+	prefix := "/remote.php/dav/files/user"
+
+	handler := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: webdav.Dir(root),
+		LockSystem: webdav.NewMemLS(),
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Like Nextcloud, nothing above the mount can be accessed
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	u, err := url.Parse(s.URL)
+	testza.AssertNoError(t, err)
+
+	d, err := FromPath("webdav://" + u.Host + prefix + "/server")
+	testza.AssertNoError(t, err)
+
+	testza.AssertNoError(t, d.MkDir(prefix+"/server/FactoryGame/Mods/Example"))
+	testza.AssertNoError(t, d.MkDir(prefix+"/server/FactoryGame/Mods/Other"))
+
+	info, err := os.Stat(filepath.Join(root, "server", "FactoryGame", "Mods", "Other"))
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, info.IsDir())
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+)
+
+const (
+	BranchStable       = "stable"
+	BranchExperimental = "experimental"
+)
+
+// GameInfo describes the game build of an installation, as read from its .version file
+type GameInfo struct {
+	Branch        string `json:"branch"`
+	BuildID       string `json:"build_id,omitempty"`
+	EngineVersion string `json:"engine_version,omitempty"`
+	Target        string `json:"target"`
+	Changelist    int    `json:"changelist"`
+	Server        bool   `json:"server"`
+}
+
+// BranchKind returns whether the build is from the stable or experimental branch, or an empty string if it can not be told
+func (g *GameInfo) BranchKind() string {
+	branch := strings.ToLower(g.Branch)
+	switch {
+	case branch == "":
+		return ""
+	case strings.Contains(branch, "exp"):
+		return BranchExperimental
+	default:
+		return BranchStable
+	}
+}
+
+// Kind returns "server" for dedicated servers and "client" otherwise
+func (g *GameInfo) Kind() string {
+	if g.Server {
+		return "server"
+	}
+	return "client"
+}
+
+// SameBuild returns true if both describe the same game build
+func (g *GameInfo) SameBuild(other *GameInfo) bool {
+	return g.Changelist == other.Changelist && g.Branch == other.Branch && g.BuildID == other.BuildID
+}
+
+func (g *GameInfo) String() string {
+	out := fmt.Sprintf("%s (%s)", g.Kind(), g.Target)
+
+	if kind := g.BranchKind(); kind != "" {
+		out += ", " + kind
+	}
+
+	return out + fmt.Sprintf(", CL%d", g.Changelist)
+}
+
+// installationLockFile is the lockfile written into installations.
+//
+// It records the game build it was resolved against, to detect game updates between applies.
+type installationLockFile struct {
+	*resolver.LockFile
+	Game *GameInfo `json:"game,omitempty"`
+}
+
+func (i *Installation) readGameVersionFile(platform *Platform) (*gameVersionFile, error) {
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := filepath.Join(i.BasePath(), platform.VersionPath)
+
+	file, err := d.Read(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading version file: %w", err)
+	}
+
+	var versionData gameVersionFile
+	if err := json.Unmarshal(file, &versionData); err != nil {
+		return nil, fmt.Errorf("failed to parse version file json: %w", err)
+	}
+
+	return &versionData, nil
+}
+
+func (i *Installation) gameInfo(platform *Platform) (*GameInfo, error) {
+	versionData, err := i.readGameVersionFile(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameInfo{
+		Branch:        versionData.BranchName,
+		BuildID:       versionData.BuildID,
+		EngineVersion: fmt.Sprintf("%d.%d.%d", versionData.MajorVersion, versionData.MinorVersion, versionData.PatchVersion),
+		Target:        platform.TargetName,
+		Changelist:    versionData.Changelist,
+		Server:        strings.HasSuffix(platform.TargetName, "Server"),
+	}, nil
+}
+
+// GameInfo returns the game build of the installation
+func (i *Installation) GameInfo(ctx *GlobalContext) (*GameInfo, error) {
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.gameInfo(platform)
+}
+
+// ResolvedGameInfo returns the game build the installation's profile was last resolved against.
+//
+// It returns nil if the profile was never resolved, or was resolved before builds were recorded.
+func (i *Installation) ResolvedGameInfo(ctx *GlobalContext) (*GameInfo, error) {
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lockFile, err := i.readLockFile(ctx, platform)
+	if err != nil || lockFile == nil {
+		return nil, err
+	}
+
+	return lockFile.Game, nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+)
+
+func TestGameInfo(t *testing.T) {
+	installation, ctx := newTestServerInstallation(t, "Game", `{"MajorVersion": 5, "MinorVersion": 3, "PatchVersion": 2, "Changelist": 365306, "BranchName": "++FactoryGame+rel-main-1.0.0", "BuildId": "a1b2"}`)
+
+	game, err := installation.GameInfo(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, &GameInfo{
+		Branch:        "++FactoryGame+rel-main-1.0.0",
+		BuildID:       "a1b2",
+		EngineVersion: "5.3.2",
+		Target:        "LinuxServer",
+		Changelist:    365306,
+		Server:        true,
+	}, game)
+	testza.AssertEqual(t, BranchStable, game.BranchKind())
+	testza.AssertEqual(t, "server (LinuxServer), stable, CL365306", game.String())
+
+	// Lockfiles written before builds were recorded are still read
+	resolved, err := installation.ResolvedGameInfo(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertNil(t, resolved)
+
+	testza.AssertNoError(t, installation.WriteLockFile(ctx, resolver.NewLockfile()))
+
+	resolved, err = installation.ResolvedGameInfo(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, game, resolved)
+
+	lockFile, err := installation.LockFile(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, lockFile)
+
+	testza.AssertNoError(t, installation.DiskInstance.Write(filepath.Join("/server", platforms[0].VersionPath), []byte(`{"Changelist": 366000, "BranchName": "++FactoryGame+dev-experimental"}`)))
+
+	game, err = installation.GameInfo(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, BranchExperimental, game.BranchKind())
+	testza.AssertFalse(t, resolved.SameBuild(game))
+}
@@ -17,6 +17,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
 	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
@@ -25,6 +26,7 @@ type InstallationsVersion int
 
 const (
 	InitialInstallationsVersion = InstallationsVersion(iota)
+	NamedInstallationsVersion
 
 	// Always last
 	nextInstallationsVersion
@@ -34,13 +36,18 @@ type Installations struct {
 	SelectedInstallation string               `json:"selected_installation"`
 	Installations        []*Installation      `json:"installations"`
 	Version              InstallationsVersion `json:"version"`
+	loaded               stateHash
 }
 
 type Installation struct {
 	DiskInstance disk.Disk `json:"-"`
+	Name         string    `json:"name"`
 	Path         string    `json:"path"`
 	Profile      string    `json:"profile"`
+	SavePath     string    `json:"save_path,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
 	Vanilla      bool      `json:"vanilla"`
+	AutoBackup   bool      `json:"auto_backup,omitempty"`
 }
 
 func InitInstallations() (*Installations, error) {
@@ -79,6 +86,11 @@ func InitInstallations() (*Installations, error) {
 		return nil, fmt.Errorf("failed to read installations: %w", err)
 	}
 
+	installationsData, err = migrateState(installationsFile, installationsData, installationsMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate installations: %w", err)
+	}
+
 	var installations Installations
 	if err := json.Unmarshal(installationsData, &installations); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal installations: %w", err)
@@ -88,6 +100,8 @@ func InitInstallations() (*Installations, error) {
 		return nil, fmt.Errorf("unknown installations version: %d", installations.Version)
 	}
 
+	installations.loaded = hashState(installationsData)
+
 	return &installations, nil
 }
 
@@ -99,6 +113,16 @@ func (i *Installations) Save() error {
 
 	installationsFile := filepath.Join(viper.GetString("local-dir"), viper.GetString("installations-file"))
 
+	unlock, err := LockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := checkStateUnmodified(installationsFile, i.loaded); err != nil {
+		return err
+	}
+
 	slog.Info("saving installations", slog.String("path", installationsFile))
 
 	installationsJSON, err := json.MarshalIndent(i, "", "  ")
@@ -106,21 +130,23 @@ func (i *Installations) Save() error {
 		return fmt.Errorf("failed to marshal installations: %w", err)
 	}
 
-	if err := os.WriteFile(installationsFile, installationsJSON, 0o755); err != nil {
+	if err := utils.WriteFileAtomic(installationsFile, installationsJSON, 0o600); err != nil {
 		return fmt.Errorf("failed to write installations: %w", err)
 	}
 
+	i.loaded = hashState(installationsJSON)
+
 	return nil
 }
 
 func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string, profile string) (*Installation, error) {
-	parsed, err := url.Parse(installPath)
-	if err != nil {
+	if _, err := url.Parse(installPath); err != nil {
 		return nil, fmt.Errorf("failed to parse path: %w", err)
 	}
 
 	absolutePath := installPath
-	if parsed.Scheme != "ftp" && parsed.Scheme != "sftp" {
+	if !disk.IsRemote(installPath) {
+		var err error
 		absolutePath, err = filepath.Abs(installPath)
 
 		if err != nil {
@@ -129,6 +155,7 @@ func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string,
 	}
 
 	installation := &Installation{
+		Name:    i.defaultInstallationName(absolutePath),
 		Path:    absolutePath,
 		Profile: profile,
 		Vanilla: false,
@@ -138,9 +165,12 @@ func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string,
 		return nil, fmt.Errorf("failed to validate installation: %w", err)
 	}
 
+	// Check for duplicates before storing any credential, so a rejected installation leaves none behind
+	storedPath := credentialFreePath(installation.Path)
+
 	found := false
 	for _, install := range i.Installations {
-		if filepath.Clean(installation.Path) == filepath.Clean(install.Path) {
+		if filepath.Clean(storedPath) == filepath.Clean(install.Path) {
 			found = true
 			break
 		}
@@ -150,14 +180,46 @@ func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string,
 		return nil, errors.New("installation already present")
 	}
 
+	if err := installation.extractCredentials(); err != nil {
+		return nil, err
+	}
+
 	i.Installations = append(i.Installations, installation)
 
 	return installation, nil
 }
 
-func (i *Installations) GetInstallation(installPath string) *Installation {
+// ExtractCredentials moves passwords embedded in installation urls into the credential store
+// and returns the number of installations that were changed
+func (i *Installations) ExtractCredentials() (int, error) {
+	changed := 0
 	for _, install := range i.Installations {
-		if install.Path == installPath {
+		oldPath := install.Path
+		if err := install.extractCredentials(); err != nil {
+			return changed, err
+		}
+
+		if install.Path != oldPath {
+			if i.SelectedInstallation == oldPath {
+				i.SelectedInstallation = install.Path
+			}
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// GetInstallation returns the installation with the given name or path
+func (i *Installations) GetInstallation(reference string) *Installation {
+	for _, install := range i.Installations {
+		if install.Name == reference {
+			return install
+		}
+	}
+
+	for _, install := range i.Installations {
+		if install.Path == reference {
 			return install
 		}
 	}
@@ -165,10 +227,12 @@ func (i *Installations) GetInstallation(installPath string) *Installation {
 	return nil
 }
 
-func (i *Installations) DeleteInstallation(installPath string) error {
+func (i *Installations) DeleteInstallation(reference string) error {
+	installation := i.GetInstallation(reference)
+
 	found := -1
 	for j, install := range i.Installations {
-		if install.Path == installPath {
+		if install == installation {
 			found = j
 			break
 		}
@@ -183,8 +247,6 @@ func (i *Installations) DeleteInstallation(installPath string) error {
 	return nil
 }
 
-var rootExecutables = []string{"FactoryGame.exe", "FactoryServer.sh", "FactoryServer.exe", "FactoryGameSteam.exe", "FactoryGameEGS.exe"}
-
 func (i *Installation) Validate(ctx *GlobalContext) error {
 	found := false
 	for _, p := range ctx.Profiles.Profiles {
@@ -207,7 +269,7 @@ func (i *Installation) Validate(ctx *GlobalContext) error {
 
 	var checkWait errgroup.Group
 
-	for _, executable := range rootExecutables {
+	for _, executable := range ctx.gameExecutables() {
 		e := executable
 		checkWait.Go(func() error {
 			exists, err := d.Exists(filepath.Join(i.BasePath(), e))
@@ -250,6 +312,15 @@ func (i *Installation) lockFilePath(ctx *GlobalContext, platform *Platform) stri
 }
 
 func (i *Installation) lockfile(ctx *GlobalContext, platform *Platform) (*resolver.LockFile, error) {
+	lockFile, err := i.readLockFile(ctx, platform)
+	if err != nil || lockFile == nil {
+		return nil, err
+	}
+
+	return lockFile.LockFile, nil
+}
+
+func (i *Installation) readLockFile(ctx *GlobalContext, platform *Platform) (*installationLockFile, error) {
 	lockfilePath := i.lockFilePath(ctx, platform)
 
 	d, err := i.GetDisk()
@@ -266,7 +337,7 @@ func (i *Installation) lockfile(ctx *GlobalContext, platform *Platform) (*resolv
 		return nil, nil
 	}
 
-	var lockFile *resolver.LockFile
+	var lockFile *installationLockFile
 	lockFileJSON, err := d.Read(lockfilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed reading lockfile: %w", err)
@@ -298,7 +369,12 @@ func (i *Installation) writeLockFile(ctx *GlobalContext, platform *Platform, loc
 		}
 	}
 
-	marshaledLockfile, err := json.MarshalIndent(lockfile, "", "  ")
+	game, err := i.gameInfo(platform)
+	if err != nil {
+		slog.Warn("failed to detect game build for lockfile", slog.String("path", i.DisplayPath()), slog.Any("err", err))
+	}
+
+	marshaledLockfile, err := json.MarshalIndent(installationLockFile{LockFile: lockfile, Game: game}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to serialize lockfile json: %w", err)
 	}
@@ -318,6 +394,12 @@ func (i *Installation) Wipe() error {
 		return err
 	}
 
+	held, err := i.lock(d)
+	if err != nil {
+		return err
+	}
+	defer held.Release()
+
 	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
 	if err := d.Remove(modsDirectory); err != nil {
 		return fmt.Errorf("failed removing Mods directory: %w", err)
@@ -327,19 +409,33 @@ func (i *Installation) Wipe() error {
 }
 
 func (i *Installation) resolveProfile(ctx *GlobalContext, platform *Platform) (*resolver.LockFile, error) {
-	lockFile, err := i.lockfile(ctx, platform)
+	previous, err := i.readLockFile(ctx, platform)
 	if err != nil {
 		return nil, err
 	}
 
 	depResolver := resolver.NewDependencyResolver(ctx.Provider)
 
-	gameVersion, err := i.getGameVersion(platform)
+	game, err := i.gameInfo(platform)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect game version: %w", err)
 	}
 
-	lockfile, err := ctx.Profiles.Profiles[i.Profile].Resolve(depResolver, lockFile, gameVersion)
+	var lockFile *resolver.LockFile
+	if previous != nil {
+		lockFile = previous.LockFile
+
+		if previous.Game != nil && !previous.Game.SameBuild(game) {
+			slog.Warn("profile was last resolved against a different game build, mod compatibility may have changed",
+				slog.String("installation", i.DisplayName()),
+				slog.String("profile", i.Profile),
+				slog.String("previous", previous.Game.String()),
+				slog.String("current", game.String()),
+			)
+		}
+	}
+
+	lockfile, err := ctx.Profiles.Profiles[i.Profile].Resolve(depResolver, lockFile, game.Changelist)
 	if err != nil {
 		return nil, fmt.Errorf("could not resolve mods: %w", err)
 	}
@@ -375,6 +471,23 @@ func (i *Installation) WriteLockFile(ctx *GlobalContext, lockfile *resolver.Lock
 	return i.writeLockFile(ctx, platform, lockfile)
 }
 
+// ResolveProfile resolves the provided profile against the game version of this installation.
+//
+// The installation lockfile is neither used nor updated.
+func (i *Installation) ResolveProfile(ctx *GlobalContext, profile *Profile) (*resolver.LockFile, error) {
+	gameVersion, err := i.GetGameVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect game version: %w", err)
+	}
+
+	lockFile, err := profile.Resolve(resolver.NewDependencyResolver(ctx.Provider), nil, gameVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve mods: %w", err)
+	}
+
+	return lockFile, nil
+}
+
 type InstallUpdateType string
 
 var (
@@ -401,6 +514,17 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 		return fmt.Errorf("failed to detect platform: %w", err)
 	}
 
+	d, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	held, err := i.lock(d)
+	if err != nil {
+		return err
+	}
+	defer held.Release()
+
 	lockfile := resolver.NewLockfile()
 
 	if !i.Vanilla {
@@ -411,9 +535,10 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 		}
 	}
 
-	d, err := i.GetDisk()
-	if err != nil {
-		return err
+	if i.AutoBackup || viper.GetBool("backup-saves") {
+		if _, err := i.BackupSaves(ctx); err != nil {
+			return fmt.Errorf("failed to back up saves: %w", err)
+		}
 	}
 
 	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
@@ -439,6 +564,10 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 				modName := entry.Name()
 				modDir := filepath.Join(modsDirectory, modName)
 				deleteWait.Go(func() error {
+					if err := held.Lost(); err != nil {
+						return err
+					}
+
 					exists, err := d.Exists(filepath.Join(modDir, ".smm"))
 					if err != nil {
 						return err
@@ -449,6 +578,8 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 						if err := d.Remove(modDir); err != nil {
 							return fmt.Errorf("failed to delete mod directory: %w", err)
 						}
+					} else {
+						slog.Warn("ignoring unmanaged mod, run installation scan to adopt or quarantine it", slog.String("mod_reference", modName))
 					}
 
 					return nil
@@ -505,6 +636,10 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 				return nil
 			}
 
+			if err := held.Lost(); err != nil {
+				return err
+			}
+
 			// Only install if a link is provided, otherwise assume mod is already installed
 			if target.Link != "" {
 				err := downloadAndExtractMod(modReference, version.Version, target.Link, target.Hash, platform.TargetName, modsDirectory, updates, downloadSemaphore, d)
@@ -640,7 +775,7 @@ func downloadAndExtractMod(modReference string, version string, link string, has
 	}
 
 	slog.Info("extracting mod", slog.String("mod_reference", modReference), slog.String("version", version), slog.String("link", link))
-	if err := utils.ExtractMod(reader, size, filepath.Join(modsDirectory, modReference), hash, extractUpdates, d); err != nil {
+	if err := utils.ExtractMod(reader, size, filepath.Join(modsDirectory, modReference), hash, archiveLimits(), extractUpdates, d); err != nil {
 		return fmt.Errorf("could not extract %s: %w", modReference, err)
 	}
 
@@ -662,6 +797,21 @@ func downloadAndExtractMod(modReference string, version string, link string, has
 	return nil
 }
 
+// archiveLimits returns the configured mod archive limits, falling back to the defaults when not configured
+func archiveLimits() utils.ArchiveLimits {
+	limits := utils.DefaultArchiveLimits
+
+	if viper.IsSet("max-mod-size") {
+		limits.MaxSize = viper.GetInt64("max-mod-size")
+	}
+
+	if viper.IsSet("max-mod-files") {
+		limits.MaxFiles = viper.GetInt("max-mod-files")
+	}
+
+	return limits
+}
+
 func (i *Installation) SetProfile(ctx *GlobalContext, profile string) error {
 	found := false
 	for _, p := range ctx.Profiles.Profiles {
@@ -693,49 +843,23 @@ type gameVersionFile struct {
 }
 
 func (i *Installation) getGameVersion(platform *Platform) (int, error) {
-	d, err := i.GetDisk()
+	versionData, err := i.readGameVersionFile(platform)
 	if err != nil {
 		return 0, err
 	}
 
-	fullPath := filepath.Join(i.BasePath(), platform.VersionPath)
-
-	file, err := d.Read(fullPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed reading version file: %w", err)
-	}
-
-	var versionData gameVersionFile
-	if err := json.Unmarshal(file, &versionData); err != nil {
-		return 0, fmt.Errorf("failed to parse version file json: %w", err)
-	}
-
 	return versionData.Changelist, nil
 }
 
 func (i *Installation) GetPlatform(ctx *GlobalContext) (*Platform, error) {
-	if err := i.Validate(ctx); err != nil {
-		return nil, fmt.Errorf("failed to validate installation: %w", err)
-	}
-
-	d, err := i.GetDisk()
+	detection, err := i.DetectPlatform(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, platform := range platforms {
-		fullPath := filepath.Join(i.BasePath(), platform.VersionPath)
-		exists, err := d.Exists(fullPath)
-		if !exists {
-			if err != nil {
-				return nil, fmt.Errorf("failed detecting version file: %w", err)
-			}
-			continue
-		}
-		return &platform, nil
-	}
+	slog.Debug("detected platform", slog.String("path", i.DisplayPath()), slog.String("reason", detection.Reason()))
 
-	return nil, errors.New("no platform detected")
+	return detection.Platform, nil
 }
 
 func (i *Installation) GetDisk() (disk.Disk, error) {
@@ -749,14 +873,83 @@ func (i *Installation) GetDisk() (disk.Disk, error) {
 }
 
 func (i *Installation) BasePath() string {
+	if !i.IsRemote() {
+		return i.Path
+	}
+
 	parsed, err := url.Parse(i.Path)
 	if err != nil {
 		return i.Path
 	}
 
-	if parsed.Scheme != "ftp" && parsed.Scheme != "sftp" {
+	return parsed.Path
+}
+
+// DisplayPath returns the installation path with any embedded password redacted
+func (i *Installation) DisplayPath() string {
+	if !i.IsRemote() {
+		return i.Path
+	}
+
+	parsed, err := url.Parse(i.Path)
+	if err != nil {
 		return i.Path
 	}
 
-	return parsed.Path
+	return parsed.Redacted()
+}
+
+// extractCredentials replaces a password embedded in the installation url with a credential store reference
+func (i *Installation) extractCredentials() error {
+	if !i.IsRemote() {
+		return nil
+	}
+
+	parsed, err := url.Parse(i.Path)
+	if err != nil {
+		return fmt.Errorf("failed to parse path: %w", err)
+	}
+
+	extracted, id, credential, ok := credentials.Extract(parsed)
+	if !ok {
+		return nil
+	}
+
+	store, err := credentials.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open credential store: %w", err)
+	}
+
+	store.Set(id, credential)
+	if err := store.Save(); err != nil {
+		return fmt.Errorf("failed to save credential store: %w", err)
+	}
+
+	i.Path = extracted.String()
+
+	return nil
+}
+
+// credentialFreePath returns the path an installation is stored under once an embedded password is moved to the credential store
+func credentialFreePath(installPath string) string {
+	if !disk.IsRemote(installPath) {
+		return installPath
+	}
+
+	parsed, err := url.Parse(installPath)
+	if err != nil {
+		return installPath
+	}
+
+	extracted, _, _, ok := credentials.Extract(parsed)
+	if !ok {
+		return installPath
+	}
+
+	return extracted.String()
+}
+
+// IsRemote returns true if the installation is not on the local filesystem
+func (i *Installation) IsRemote() bool {
+	return disk.IsRemote(i.Path)
 }
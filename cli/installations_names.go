@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// Names and tags can never contain path separators, so they can not be confused with installation paths
+var installationNamePattern = regexp.MustCompile(`^[a-zA-Z\d][a-zA-Z\d._-]*$`)
+
+var installationNameCleaner = regexp.MustCompile(`[^a-zA-Z\d._-]+`)
+
+func validateInstallationName(name string) error {
+	if !installationNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid name %q: must start with a letter or digit and only contain letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// defaultInstallationName derives a unique name from the last element of the installation path
+func (i *Installations) defaultInstallationName(installPath string) string {
+	return uniqueInstallationName(installationBaseName(installPath), func(name string) bool {
+		return i.nameTaken(name, nil)
+	})
+}
+
+// installationBaseName turns the last element of the installation path, or the host of a remote path, into a valid name
+func installationBaseName(installPath string) string {
+	base := filepath.Base(installPath)
+	if disk.IsRemote(installPath) {
+		if parsed, err := url.Parse(installPath); err == nil {
+			base = filepath.Base(parsed.Path)
+			if base == "." || base == "/" {
+				base = parsed.Hostname()
+			}
+		}
+	}
+
+	name := strings.Trim(installationNameCleaner.ReplaceAllLiteralString(base, "-"), "-._")
+	if name == "" {
+		name = "installation"
+	}
+
+	return name
+}
+
+func uniqueInstallationName(name string, taken func(string) bool) string {
+	candidate := name
+	for n := 2; taken(candidate); n++ {
+		candidate = name + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
+
+func (i *Installations) nameTaken(name string, except *Installation) bool {
+	for _, install := range i.Installations {
+		if install != except && install.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameInstallation changes the name of an installation, which must be unique
+func (i *Installations) RenameInstallation(installation *Installation, name string) error {
+	if err := validateInstallationName(name); err != nil {
+		return err
+	}
+
+	if i.nameTaken(name, installation) {
+		return fmt.Errorf("installation with name %s already exists", name)
+	}
+
+	installation.Name = name
+
+	return nil
+}
+
+// SelectInstallations returns the installations matching any of the names or paths, followed by those with any of the tags.
+//
+// If neither are given, every installation is returned.
+func (i *Installations) SelectInstallations(references []string, tags []string) ([]*Installation, error) {
+	if len(references) == 0 && len(tags) == 0 {
+		return i.Installations, nil
+	}
+
+	selected := make([]*Installation, 0)
+
+	for _, reference := range references {
+		installation := i.GetInstallation(reference)
+		if installation == nil {
+			return nil, fmt.Errorf("installation %s not found", reference)
+		}
+
+		if !slices.Contains(selected, installation) {
+			selected = append(selected, installation)
+		}
+	}
+
+	for _, tag := range tags {
+		found := false
+		for _, installation := range i.Installations {
+			if !installation.HasTag(tag) {
+				continue
+			}
+
+			found = true
+			if !slices.Contains(selected, installation) {
+				selected = append(selected, installation)
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no installations tagged %s", tag)
+		}
+	}
+
+	return selected, nil
+}
+
+func (i *Installation) HasTag(tag string) bool {
+	return slices.Contains(i.Tags, tag)
+}
+
+// AddTags adds the tags to the installation, ignoring ones it already has
+func (i *Installation) AddTags(tags ...string) error {
+	for _, tag := range tags {
+		if err := validateInstallationName(tag); err != nil {
+			return errors.New("invalid tag: " + err.Error())
+		}
+
+		if !i.HasTag(tag) {
+			i.Tags = append(i.Tags, tag)
+		}
+	}
+
+	sort.Strings(i.Tags)
+
+	return nil
+}
+
+// RemoveTags removes the tags from the installation, ignoring ones it does not have
+func (i *Installation) RemoveTags(tags ...string) {
+	i.Tags = slices.DeleteFunc(i.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+
+	if len(i.Tags) == 0 {
+		i.Tags = nil
+	}
+}
+
+// DisplayName returns the name of the installation, falling back to its path
+func (i *Installation) DisplayName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return i.DisplayPath()
+}
@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
 	"goftp.io/server/v2"
 	"goftp.io/server/v2/driver/file"
 
 	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 )
 
 // NOTE:
@@ -74,6 +76,51 @@ func TestAddLocalInstallation(t *testing.T) {
 	testza.AssertNoError(t, err)
 }
 
+// useRemoteTestSettings trusts the test servers' host keys and unlocks the credential store for the duration of the test
+func useRemoteTestSettings(t *testing.T) {
+	t.Helper()
+
+	previous := map[string]interface{}{}
+	for key, value := range map[string]interface{}{
+		"known-hosts-file":       filepath.Join(t.TempDir(), "known_hosts"),
+		"accept-host-key":        true,
+		"credentials-passphrase": "ficsit-test",
+	} {
+		previous[key] = viper.Get(key)
+		viper.Set(key, value)
+	}
+
+	t.Cleanup(func() {
+		for key, value := range previous {
+			viper.Set(key, value)
+		}
+	})
+}
+
+// newTestServerInstallation creates a Linux dedicated server installation at /server on a memory disk,
+// using a new profile of the given name. version is the content of the game's version file.
+func newTestServerInstallation(t *testing.T, profile string, version string) (*Installation, *GlobalContext) {
+	t.Helper()
+
+	d := disk.NewMemory()
+
+	testza.AssertNoError(t, d.MkDir(filepath.Join("/server", "Engine", "Binaries", "Linux")))
+	testza.AssertNoError(t, d.Write(filepath.Join("/server", "FactoryServer.sh"), []byte{}))
+	testza.AssertNoError(t, d.Write(filepath.Join("/server", platforms[0].VersionPath), []byte(version)))
+
+	profiles := &Profiles{Profiles: map[string]*Profile{}}
+	_, err := profiles.AddProfile(profile)
+	testza.AssertNoError(t, err)
+
+	installation := &Installation{
+		Path:         "/server",
+		Profile:      profile,
+		DiskInstance: d,
+	}
+
+	return installation, &GlobalContext{Profiles: profiles}
+}
+
 func TestAddFTPInstallation(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		// Not supported
@@ -99,6 +146,8 @@ func TestAddFTPInstallation(t *testing.T) {
 
 	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
 	if serverLocation != "" {
+		useRemoteTestSettings(t)
+
 		driver, err := file.NewDriver(serverLocation)
 		testza.AssertNoError(t, err)
 
@@ -164,6 +213,8 @@ func TestAddSFTPInstallation(t *testing.T) {
 
 	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
 	if serverLocation != "" {
+		useRemoteTestSettings(t)
+
 		time.Sleep(time.Second)
 		testza.AssertNoError(t, os.RemoveAll(filepath.Join(serverLocation, "FactoryGame", "Mods")))
 		time.Sleep(time.Second)
@@ -184,3 +235,37 @@ func TestAddSFTPInstallation(t *testing.T) {
 	err = ctx.Wipe()
 	testza.AssertNoError(t, err)
 }
+
+func TestInstallationNamesAndTags(t *testing.T) {
+	useStateFixture(t, "installations-v0.json", viper.GetString("installations-file"))
+
+	installations, err := InitInstallations()
+	testza.AssertNoError(t, err)
+
+	remote := installations.GetInstallation("example.com")
+	testza.AssertNotNil(t, remote)
+
+	testza.AssertNotNil(t, installations.RenameInstallation(remote, "SatisfactoryDedicatedServer"))
+	testza.AssertNotNil(t, installations.RenameInstallation(remote, "../remote"))
+	testza.AssertNoError(t, installations.RenameInstallation(remote, "remote"))
+	testza.AssertEqual(t, remote, installations.GetInstallation("remote"))
+	testza.AssertEqual(t, installations.Installations[1], installations.GetInstallation("/srv/SatisfactoryDedicatedServer"))
+
+	testza.AssertNoError(t, remote.AddTags("servers", "eu"))
+	testza.AssertNoError(t, installations.Installations[1].AddTags("servers"))
+	testza.AssertNotNil(t, remote.AddTags("two words"))
+	testza.AssertEqual(t, []string{"eu", "servers"}, remote.Tags)
+
+	selected, err := installations.SelectInstallations([]string{"SatisfactoryDedicatedServer"}, []string{"servers"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []*Installation{installations.Installations[0], installations.Installations[1], remote}, selected)
+
+	_, err = installations.SelectInstallations(nil, []string{"missing"})
+	testza.AssertNotNil(t, err)
+
+	_, err = installations.SelectInstallations([]string{"missing"}, nil)
+	testza.AssertNotNil(t, err)
+
+	remote.RemoveTags("servers", "eu")
+	testza.AssertNil(t, remote.Tags)
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+const installLockFile = ".ficsit-lock.json"
+
+var (
+	// installLockStaleAfter is how long a lock can go without a heartbeat before it is considered abandoned
+	installLockStaleAfter = 10 * time.Minute
+
+	installLockHeartbeat = 2 * time.Minute
+)
+
+// InstallLock is written into an installation while it is being modified,
+// so runs from other processes or machines do not interleave their changes
+type InstallLock struct {
+	Acquired time.Time `json:"acquired"`
+	Updated  time.Time `json:"updated"`
+	ID       string    `json:"id"`
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+}
+
+// ErrInstallLockLost is returned when another process took over the install lock while it was held
+var ErrInstallLockLost = errors.New("installation lock was taken over by another process")
+
+type InstallLockedError struct {
+	Path string
+	Lock InstallLock
+}
+
+func (e *InstallLockedError) Error() string {
+	return fmt.Sprintf("installation %s is locked by %s (pid %d) since %s, re-run with --force-unlock if that process is gone", e.Path, e.Lock.Host, e.Lock.PID, e.Lock.Acquired.Format(time.RFC3339))
+}
+
+// stale returns true if the process holding the lock is gone
+func (l InstallLock) stale(hostname string) bool {
+	if time.Since(l.Updated) > installLockStaleAfter {
+		return true
+	}
+
+	return l.Host == hostname && !processAlive(l.PID)
+}
+
+// heldInstallLock is an install lock acquired by this process
+type heldInstallLock struct {
+	disk    disk.Disk
+	lost    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	path    string
+	display string
+	lock    InstallLock
+}
+
+// lock acquires the install lock of the installation, taking over stale locks.
+//
+// The lock is kept alive with a heartbeat until it is released.
+func (i *Installation) lock(d disk.Disk) (*heldInstallLock, error) {
+	lockPath := filepath.Join(i.BasePath(), installLockFile)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate lock id: %w", err)
+	}
+
+	now := time.Now()
+	lock := InstallLock{
+		Acquired: now,
+		Updated:  now,
+		ID:       hex.EncodeToString(id),
+		Host:     hostname,
+		PID:      os.Getpid(),
+	}
+
+	if viper.GetBool("force-unlock") {
+		slog.Warn("forcibly removing installation lock", slog.String("path", i.DisplayPath()))
+		if err := d.Remove(lockPath); err != nil {
+			return nil, fmt.Errorf("failed to remove installation lock: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := writeInstallLock(d, lockPath, lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to write installation lock: %w", err)
+		}
+
+		existing, err := readInstallLock(d, lockPath)
+		if errors.Is(err, os.ErrNotExist) && attempt == 0 {
+			// Released while we were trying to take it
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read installation lock, re-run with --force-unlock if no other process is running: %w", err)
+		}
+
+		if attempt > 0 || !existing.stale(hostname) {
+			return nil, &InstallLockedError{Path: i.DisplayPath(), Lock: existing}
+		}
+
+		slog.Warn("removing stale installation lock", slog.String("path", i.DisplayPath()), slog.String("host", existing.Host), slog.Int("pid", existing.PID), slog.Time("updated", existing.Updated))
+		if err := d.Remove(lockPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale installation lock: %w", err)
+		}
+	}
+
+	// Exclusive creation is not atomic on every disk, so make sure another process did not overwrite the lock
+	written, err := readInstallLock(d, lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installation lock: %w", err)
+	}
+
+	if written.ID != lock.ID {
+		return nil, &InstallLockedError{Path: i.DisplayPath(), Lock: written}
+	}
+
+	held := &heldInstallLock{
+		disk:    d,
+		path:    lockPath,
+		display: i.DisplayPath(),
+		lock:    lock,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go held.heartbeat()
+
+	return held, nil
+}
+
+// heartbeat refreshes the lock until it is released or taken over by another process
+func (h *heldInstallLock) heartbeat() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(installLockHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			// Never overwrite a lock that was taken over after a stalled refresh or --force-unlock
+			current, err := readInstallLock(h.disk, h.path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("failed to read installation lock", slog.String("path", h.display), slog.Any("err", err))
+				continue
+			}
+
+			if err != nil || current.ID != h.lock.ID {
+				slog.Error("installation lock was lost, cancelling", slog.String("path", h.display), slog.String("host", current.Host), slog.Int("pid", current.PID))
+				close(h.lost)
+				return
+			}
+
+			h.lock.Updated = time.Now()
+			if err := writeInstallLock(h.disk, h.path, h.lock, os.O_CREATE|os.O_TRUNC|os.O_WRONLY); err != nil {
+				slog.Warn("failed to refresh installation lock", slog.String("path", h.display), slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// Lost returns ErrInstallLockLost once another process took over the lock,
+// changes to the installation must stop at that point
+func (h *heldInstallLock) Lost() error {
+	select {
+	case <-h.lost:
+		return ErrInstallLockLost
+	default:
+		return nil
+	}
+}
+
+// Release stops the heartbeat and removes the lock if it is still held
+func (h *heldInstallLock) Release() {
+	close(h.stop)
+	<-h.done
+
+	if h.Lost() != nil {
+		return
+	}
+
+	current, err := readInstallLock(h.disk, h.path)
+	if err != nil {
+		slog.Warn("failed to read installation lock", slog.String("path", h.display), slog.Any("err", err))
+		return
+	}
+
+	if current.ID != h.lock.ID {
+		slog.Warn("installation lock was taken over by another process", slog.String("path", h.display), slog.String("host", current.Host), slog.Int("pid", current.PID))
+		return
+	}
+
+	if err := h.disk.Remove(h.path); err != nil {
+		slog.Warn("failed to remove installation lock", slog.String("path", h.display), slog.Any("err", err))
+	}
+}
+
+func writeInstallLock(d disk.Disk, lockPath string, lock InstallLock, flag int) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installation lock: %w", err)
+	}
+
+	f, err := d.Open(lockPath, flag)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write installation lock: %w", err)
+	}
+
+	// Remote disks only report upload failures when closing
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write installation lock: %w", err)
+	}
+
+	return nil
+}
+
+func readInstallLock(d disk.Disk, lockPath string) (InstallLock, error) {
+	var lock InstallLock
+
+	data, err := d.Read(lockPath)
+	if err != nil {
+		return lock, err //nolint:wrapcheck
+	}
+
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, fmt.Errorf("failed to parse installation lock: %w", err)
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+func TestInstallationLock(t *testing.T) {
+	d := disk.NewMemory()
+	testza.AssertNoError(t, d.MkDir(filepath.Join("/server", "FactoryGame", "Mods")))
+
+	installation := &Installation{Path: "/server", DiskInstance: d}
+	lockPath := filepath.Join("/server", installLockFile)
+
+	hostname, err := os.Hostname()
+	testza.AssertNoError(t, err)
+
+	acquired, err := installation.lock(d)
+	testza.AssertNoError(t, err)
+
+	held, err := readInstallLock(d, lockPath)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, hostname, held.Host)
+	testza.AssertEqual(t, os.Getpid(), held.PID)
+
+	_, err = installation.lock(d)
+	var lockedErr *InstallLockedError
+	testza.AssertTrue(t, errors.As(err, &lockedErr), err)
+	testza.AssertEqual(t, held.ID, lockedErr.Lock.ID)
+
+	testza.AssertTrue(t, errors.As(installation.Wipe(), &lockedErr), "wiping fails while the lock is held")
+
+	_, err = installation.QuarantineMods(nil)
+	testza.AssertTrue(t, errors.As(err, &lockedErr), "quarantining fails while the lock is held")
+
+	exists, err := d.Exists(filepath.Join("/server", "FactoryGame", "Mods"))
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	acquired.Release()
+
+	exists, err = d.Exists(lockPath)
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	// A lock without a recent heartbeat is taken over
+	testza.AssertNoError(t, writeInstallLock(d, lockPath, InstallLock{ID: "old", Host: "elsewhere", PID: 1, Updated: time.Now().Add(-time.Hour)}, os.O_CREATE|os.O_WRONLY))
+
+	acquired, err = installation.lock(d)
+	testza.AssertNoError(t, err)
+	acquired.Release()
+
+	// A lock held by a process of this machine that no longer runs is taken over
+	testza.AssertNoError(t, writeInstallLock(d, lockPath, InstallLock{ID: "dead", Host: hostname, PID: 1 << 30, Updated: time.Now()}, os.O_CREATE|os.O_WRONLY))
+
+	acquired, err = installation.lock(d)
+	testza.AssertNoError(t, err)
+	acquired.Release()
+
+	// A live lock from another machine requires forcing
+	testza.AssertNoError(t, writeInstallLock(d, lockPath, InstallLock{ID: "live", Host: "elsewhere", PID: 1, Updated: time.Now()}, os.O_CREATE|os.O_WRONLY))
+
+	_, err = installation.lock(d)
+	testza.AssertTrue(t, errors.As(err, &lockedErr), err)
+
+	viper.Set("force-unlock", true)
+	defer viper.Set("force-unlock", false)
+
+	testza.AssertNoError(t, installation.Wipe())
+
+	exists, err = d.Exists(lockPath)
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists, "the lock is released after wiping")
+}
+
+func TestInstallationLockTakeover(t *testing.T) {
+	heartbeat := installLockHeartbeat
+	installLockHeartbeat = 10 * time.Millisecond
+	defer func() { installLockHeartbeat = heartbeat }()
+
+	d := disk.NewMemory()
+	testza.AssertNoError(t, d.MkDir(filepath.Join("/server", "FactoryGame", "Mods")))
+
+	installation := &Installation{Path: "/server", DiskInstance: d}
+	lockPath := filepath.Join("/server", installLockFile)
+
+	acquired, err := installation.lock(d)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, acquired.Lost())
+
+	// Another process forcibly took the lock while a refresh stalled
+	testza.AssertNoError(t, writeInstallLock(d, lockPath, InstallLock{ID: "other", Host: "elsewhere", PID: 1, Updated: time.Now()}, os.O_CREATE|os.O_TRUNC|os.O_WRONLY))
+
+	for deadline := time.Now().Add(5 * time.Second); acquired.Lost() == nil && time.Now().Before(deadline); {
+		time.Sleep(installLockHeartbeat)
+	}
+	testza.AssertTrue(t, errors.Is(acquired.Lost(), ErrInstallLockLost))
+
+	time.Sleep(5 * installLockHeartbeat)
+	acquired.Release()
+
+	current, err := readInstallLock(d, lockPath)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "other", current.ID, "the new holder's lock is neither refreshed nor removed")
+}
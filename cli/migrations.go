@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// stateMigration upgrades a decoded state file by a single version
+type stateMigration func(state map[string]interface{}) error
+
+// profilesMigrations are applied in order to profiles.json, the migration at index i upgrades from version i to i+1
+var profilesMigrations = []stateMigration{}
+
+// installationsMigrations are applied in order to installations.json, the migration at index i upgrades from version i to i+1
+var installationsMigrations = []stateMigration{
+	nameInstallations,
+}
+
+// migrateState upgrades the state file contents to the latest version.
+//
+// The original file is backed up next to it before it is rewritten.
+// Files of an unknown version are returned unchanged, for the loader to reject.
+func migrateState(stateFile string, data []byte, migrations []stateMigration) ([]byte, error) {
+	var state map[string]interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", stateFile, err)
+	}
+
+	version := 0
+	if v, ok := state["version"].(float64); ok {
+		version = int(v)
+	}
+
+	if version >= len(migrations) {
+		return data, nil
+	}
+
+	slog.Info("migrating state file", slog.String("path", stateFile), slog.Int("from", version), slog.Int("to", len(migrations)))
+
+	for i := version; i < len(migrations); i++ {
+		if err := migrations[i](state); err != nil {
+			return nil, fmt.Errorf("failed to apply migration %d to %s: %w", i, stateFile, err)
+		}
+
+		state["version"] = i + 1
+	}
+
+	migrated, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", stateFile, err)
+	}
+
+	if viper.GetBool("dry-run") {
+		slog.Info("dry-run: skipping migrated state saving", slog.String("path", stateFile))
+		return migrated, nil
+	}
+
+	unlock, err := LockState()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	backupFile := fmt.Sprintf("%s.v%d.bak", stateFile, version)
+	if err := utils.WriteFileAtomic(backupFile, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %w", stateFile, err)
+	}
+
+	if err := utils.WriteFileAtomic(stateFile, migrated, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write migrated %s: %w", stateFile, err)
+	}
+
+	return migrated, nil
+}
+
+// nameInstallations gives every installation a unique name derived from its path
+func nameInstallations(state map[string]interface{}) error {
+	installations, _ := state["installations"].([]interface{})
+
+	taken := make(map[string]bool)
+	for _, entry := range installations {
+		installation, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid installation: %v", entry)
+		}
+
+		if name, _ := installation["name"].(string); name != "" {
+			taken[name] = true
+		}
+	}
+
+	for _, entry := range installations {
+		installation := entry.(map[string]interface{})
+
+		if name, _ := installation["name"].(string); name != "" {
+			continue
+		}
+
+		path, _ := installation["path"].(string)
+		name := uniqueInstallationName(installationBaseName(path), func(name string) bool {
+			return taken[name]
+		})
+
+		taken[name] = true
+		installation["name"] = name
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+)
+
+// useStateFixture copies a state file fixture into a temporary local directory
+func useStateFixture(t *testing.T, fixture string, stateFile string) string {
+	t.Helper()
+
+	localDir := viper.GetString("local-dir")
+	viper.Set("local-dir", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("local-dir", localDir)
+	})
+
+	data, err := os.ReadFile(filepath.Join("testdata", "migrations", fixture))
+	testza.AssertNoError(t, err)
+
+	path := filepath.Join(viper.GetString("local-dir"), stateFile)
+	testza.AssertNoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func TestStateMigrationsCoverEveryVersion(t *testing.T) {
+	testza.AssertEqual(t, int(nextProfilesVersion)-1, len(profilesMigrations))
+	testza.AssertEqual(t, int(nextInstallationsVersion)-1, len(installationsMigrations))
+}
+
+func TestMigrateInstallationsFixtures(t *testing.T) {
+	for version := InitialInstallationsVersion; version < nextInstallationsVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			path := useStateFixture(t, fmt.Sprintf("installations-v%d.json", version), viper.GetString("installations-file"))
+
+			installations, err := InitInstallations()
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, nextInstallationsVersion-1, installations.Version)
+			testza.AssertNotZero(t, len(installations.Installations))
+
+			for _, installation := range installations.Installations {
+				testza.AssertNoError(t, validateInstallationName(installation.Name))
+			}
+
+			_, err = os.Stat(fmt.Sprintf("%s.v%d.bak", path, version))
+			if version == nextInstallationsVersion-1 {
+				testza.AssertTrue(t, os.IsNotExist(err), "files at the latest version are not rewritten")
+			} else {
+				testza.AssertNoError(t, err)
+			}
+		})
+	}
+
+	useStateFixture(t, "installations-v0.json", viper.GetString("installations-file"))
+
+	installations, err := InitInstallations()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "/games/SatisfactoryDedicatedServer", installations.SelectedInstallation)
+	testza.AssertEqual(t, []*Installation{
+		{Name: "SatisfactoryDedicatedServer", Path: "/games/SatisfactoryDedicatedServer", Profile: "Default"},
+		{Name: "SatisfactoryDedicatedServer-2", Path: "/srv/SatisfactoryDedicatedServer", Profile: "Modded", Vanilla: true, AutoBackup: true},
+		{Name: "example.com", Path: "sftp://user@example.com:22/", Profile: "Default"},
+	}, installations.Installations)
+}
+
+func TestMigrateProfilesFixtures(t *testing.T) {
+	for version := InitialProfilesVersion; version < nextProfilesVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			useStateFixture(t, fmt.Sprintf("profiles-v%d.json", version), viper.GetString("profiles-file"))
+
+			profiles, err := InitProfiles()
+			testza.AssertNoError(t, err)
+			testza.AssertEqual(t, nextProfilesVersion-1, profiles.Version)
+			testza.AssertEqual(t, "Modded", profiles.SelectedProfile)
+			testza.AssertEqual(t, ">=1.6.5", profiles.GetProfile("Modded").Mods["AreaActions"].Version)
+		})
+	}
+}
+
+func TestMigrateStateRejectsFailedMigration(t *testing.T) {
+	path := useStateFixture(t, "installations-v0.json", viper.GetString("installations-file"))
+
+	original, err := os.ReadFile(path)
+	testza.AssertNoError(t, err)
+
+	_, err = migrateState(path, original, []stateMigration{
+		func(map[string]interface{}) error {
+			return fmt.Errorf("broken")
+		},
+	})
+	testza.AssertNotNil(t, err)
+
+	current, err := os.ReadFile(path)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, original, current, "a failed migration leaves the file untouched")
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+const maxModSearchCandidates = 5
+
+// ResolveModReference returns the mod reference for the provided query.
+//
+// Exact mod references are returned as-is, otherwise the provider is searched
+// and a mod is picked if its reference or name matches the query case-insensitively
+// or if it is the only search result.
+//
+// Returns an error listing the closest candidates if the query is ambiguous.
+func ResolveModReference(ctx context.Context, p provider.Provider, query string) (string, error) {
+	exact, err := p.Mods(ctx, ficsit.ModFilter{
+		References: []string{query},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up mod %s: %w", query, err)
+	}
+
+	for _, mod := range exact.Mods.Mods {
+		if mod.Mod_reference == query {
+			return mod.Mod_reference, nil
+		}
+	}
+
+	search, err := p.Mods(ctx, ficsit.ModFilter{
+		Search: query,
+		Limit:  25,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search for mod %s: %w", query, err)
+	}
+
+	mods := search.Mods.Mods
+	if len(mods) == 0 {
+		return "", fmt.Errorf("no mod found matching %s", query)
+	}
+
+	for _, mod := range mods {
+		if strings.EqualFold(mod.Mod_reference, query) || strings.EqualFold(mod.Name, query) {
+			return mod.Mod_reference, nil
+		}
+	}
+
+	if len(mods) == 1 {
+		return mods[0].Mod_reference, nil
+	}
+
+	names := make([]string, len(mods))
+	for i, mod := range mods {
+		names[i] = mod.Name
+	}
+
+	candidates := make([]string, 0, maxModSearchCandidates)
+	for _, match := range fuzzy.Find(query, names) {
+		if len(candidates) == maxModSearchCandidates {
+			break
+		}
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", mods[match.Index].Name, mods[match.Index].Mod_reference))
+	}
+
+	if len(candidates) == 0 {
+		for _, mod := range mods {
+			if len(candidates) == maxModSearchCandidates {
+				break
+			}
+			candidates = append(candidates, fmt.Sprintf("%s (%s)", mod.Name, mod.Mod_reference))
+		}
+	}
+
+	return "", fmt.Errorf("ambiguous mod %s, did you mean: %s", query, strings.Join(candidates, ", "))
+}
@@ -1,49 +1,224 @@
 package cli
 
-import "path/filepath"
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/spf13/viper"
+)
+
+// BuiltinPlatforms is the source of the platform definitions shipped with ficsit
+const BuiltinPlatforms = "built-in"
+
+// Platform describes how to recognize one kind of game installation
 type Platform struct {
-	VersionPath  string
-	LockfilePath string
-	TargetName   string
-}
-
-var platforms = []Platform{
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Linux", "UnrealServer-Linux-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "LinuxServer",
-	},
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Win64", "UnrealServer-Win64-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "WindowsServer",
-	},
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Win64", "FactoryGame-Win64-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "Windows",
-	},
-	// Update 9 stuff below
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Linux", "FactoryServer-Linux-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "LinuxServer",
-	},
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Win64", "FactoryServer-Win64-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "WindowsServer",
-	},
-	// 1.0 stuff
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Win64", "FactoryGameSteam-Win64-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "Windows",
-	},
-	{
-		VersionPath:  filepath.Join("Engine", "Binaries", "Win64", "FactoryGameEGS-Win64-Shipping.version"),
-		LockfilePath: filepath.Join("FactoryGame", "Mods"),
-		TargetName:   "Windows",
-	},
+	Name         string   `json:"name"`
+	VersionPath  string   `json:"version_path"`
+	LockfilePath string   `json:"lockfile_path"`
+	TargetName   string   `json:"target_name"`
+	Executables  []string `json:"executables"`
+
+	// Source is where the definition was loaded from
+	Source string `json:"-"`
+}
+
+type platformsFile struct {
+	Platforms []Platform `json:"platforms"`
+}
+
+//go:embed platforms.json
+var builtinPlatformsJSON []byte
+
+// platforms are the built-in platform definitions
+var platforms = mustParsePlatforms(builtinPlatformsJSON, BuiltinPlatforms)
+
+func mustParsePlatforms(data []byte, source string) []Platform {
+	parsed, err := parsePlatforms(data, source)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func parsePlatforms(data []byte, source string) ([]Platform, error) {
+	var file platformsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse platforms json: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for idx := range file.Platforms {
+		platform := &file.Platforms[idx]
+
+		if platform.Name == "" {
+			return nil, fmt.Errorf("platform %d has no name", idx)
+		}
+
+		if names[platform.Name] {
+			return nil, fmt.Errorf("platform %s is defined twice", platform.Name)
+		}
+		names[platform.Name] = true
+
+		if platform.VersionPath == "" || platform.TargetName == "" || len(platform.Executables) == 0 {
+			return nil, fmt.Errorf("platform %s must have a version_path, target_name and executables", platform.Name)
+		}
+
+		if platform.LockfilePath == "" {
+			platform.LockfilePath = filepath.Join("FactoryGame", "Mods")
+		}
+
+		platform.VersionPath = filepath.FromSlash(platform.VersionPath)
+		platform.LockfilePath = filepath.FromSlash(platform.LockfilePath)
+		platform.Source = source
+	}
+
+	return file.Platforms, nil
+}
+
+// LoadPlatforms returns the built-in platform definitions extended by the platforms file in the local directory.
+//
+// User definitions are checked first, and replace built-in definitions with the same name.
+func LoadPlatforms() ([]Platform, error) {
+	if viper.GetString("platforms-file") == "" {
+		return platforms, nil
+	}
+
+	platformsFile := filepath.Join(viper.GetString("local-dir"), viper.GetString("platforms-file"))
+
+	data, err := os.ReadFile(platformsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return platforms, nil
+		}
+		return nil, fmt.Errorf("failed to read platforms file: %w", err)
+	}
+
+	custom, err := parsePlatforms(data, platformsFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid platforms file %s: %w", platformsFile, err)
+	}
+
+	return mergePlatforms(platforms, custom), nil
+}
+
+func mergePlatforms(builtin []Platform, custom []Platform) []Platform {
+	overridden := make(map[string]bool, len(custom))
+	for _, platform := range custom {
+		overridden[platform.Name] = true
+	}
+
+	merged := append([]Platform{}, custom...)
+	for _, platform := range builtin {
+		if !overridden[platform.Name] {
+			merged = append(merged, platform)
+		}
+	}
+
+	return merged
+}
+
+// platformDefinitions returns the loaded platform definitions, or the built-in ones if none were loaded
+func (g *GlobalContext) platformDefinitions() []Platform {
+	if g.Platforms == nil {
+		return platforms
+	}
+	return g.Platforms
+}
+
+// gameExecutables returns the executables of every platform definition
+func (g *GlobalContext) gameExecutables() []string {
+	seen := make(map[string]bool)
+	executables := make([]string, 0)
+	for _, platform := range g.platformDefinitions() {
+		for _, executable := range platform.Executables {
+			if !seen[executable] {
+				seen[executable] = true
+				executables = append(executables, executable)
+			}
+		}
+	}
+	return executables
+}
+
+// PlatformDetection reports which platform definition matched an installation and why
+type PlatformDetection struct {
+	Platform *Platform
+
+	// VersionFile is the version file of the platform that was found
+	VersionFile string
+
+	// Executables are the executables of the platform that were found
+	Executables []string
+
+	// Checked are the names of the definitions that were tried before the match
+	Checked []string
+}
+
+func (p *PlatformDetection) Reason() string {
+	reason := fmt.Sprintf("matched %s (%s) by %s", p.Platform.Name, p.Platform.Source, p.VersionFile)
+
+	if len(p.Executables) > 0 {
+		reason += " and " + strings.Join(p.Executables, ", ")
+	} else {
+		reason += ", none of its executables were found"
+	}
+
+	if len(p.Checked) > 0 {
+		reason += ", after checking " + strings.Join(p.Checked, ", ")
+	}
+
+	return reason
+}
+
+// DetectPlatform returns the first platform definition whose version file exists in the installation
+func (i *Installation) DetectPlatform(ctx *GlobalContext) (*PlatformDetection, error) {
+	if err := i.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to validate installation: %w", err)
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := ctx.platformDefinitions()
+	checked := make([]string, 0, len(definitions))
+
+	for idx := range definitions {
+		platform := definitions[idx]
+
+		exists, err := d.Exists(filepath.Join(i.BasePath(), platform.VersionPath))
+		if !exists {
+			if err != nil {
+				return nil, fmt.Errorf("failed detecting version file: %w", err)
+			}
+			checked = append(checked, platform.Name)
+			continue
+		}
+
+		detection := &PlatformDetection{
+			Platform:    &platform,
+			VersionFile: platform.VersionPath,
+			Checked:     checked,
+		}
+
+		for _, executable := range platform.Executables {
+			exists, err := d.Exists(filepath.Join(i.BasePath(), executable))
+			if err != nil {
+				return nil, fmt.Errorf("failed reading %s: %w", executable, err)
+			}
+			if exists {
+				detection.Executables = append(detection.Executables, executable)
+			}
+		}
+
+		return detection, nil
+	}
+
+	return nil, errors.New("no platform detected, checked " + strings.Join(checked, ", "))
 }
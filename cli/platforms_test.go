@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+func TestLoadPlatforms(t *testing.T) {
+	localDir := viper.GetString("local-dir")
+	viper.Set("local-dir", t.TempDir())
+	defer viper.Set("local-dir", localDir)
+
+	loaded, err := LoadPlatforms()
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, platforms, loaded, "only built-in platforms without a platforms file")
+
+	platformsFile := filepath.Join(viper.GetString("local-dir"), viper.GetString("platforms-file"))
+	testza.AssertNoError(t, os.WriteFile(platformsFile, []byte(`{"platforms": [
+		{"name": "linux-server", "version_path": "Engine/Binaries/Linux/FactoryServer-Linux-Shipping.version", "target_name": "LinuxServer", "executables": ["FactoryServer.sh"]},
+		{"name": "linux-server-next", "version_path": "Engine/Binaries/Linux/FactoryServerNext-Linux-Shipping.version", "target_name": "LinuxServer", "executables": ["FactoryServerNext.sh"]}
+	]}`), 0o644))
+
+	loaded, err = LoadPlatforms()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, loaded, len(platforms)+1)
+	testza.AssertEqual(t, "linux-server", loaded[0].Name)
+	testza.AssertEqual(t, platformsFile, loaded[0].Source)
+	testza.AssertEqual(t, filepath.Join("FactoryGame", "Mods"), loaded[0].LockfilePath)
+	testza.AssertEqual(t, "linux-server-next", loaded[1].Name)
+
+	for _, platform := range loaded[2:] {
+		testza.AssertNotEqual(t, "linux-server", platform.Name, "overridden built-in definitions are dropped")
+	}
+
+	testza.AssertNoError(t, os.WriteFile(platformsFile, []byte(`{"platforms": [{"name": "broken"}]}`), 0o644))
+
+	_, err = LoadPlatforms()
+	testza.AssertNotNil(t, err)
+
+	// A new server build that only the user definition knows about
+	d := disk.NewMemory()
+	testza.AssertNoError(t, d.MkDir(filepath.Join("/server", "Engine", "Binaries", "Linux")))
+	testza.AssertNoError(t, d.Write(filepath.Join("/server", "FactoryServerNext.sh"), []byte{}))
+
+	profiles := &Profiles{Profiles: map[string]*Profile{}}
+	_, err = profiles.AddProfile("Platform")
+	testza.AssertNoError(t, err)
+
+	ctx := &GlobalContext{Profiles: profiles}
+
+	installation := &Installation{
+		Path:         "/server",
+		Profile:      "Platform",
+		DiskInstance: d,
+	}
+
+	_, err = installation.DetectPlatform(ctx)
+	testza.AssertNotNil(t, err, "unknown executables are not an installation")
+
+	ctx.Platforms = mergePlatforms(platforms, mustParsePlatforms([]byte(`{"platforms": [
+		{"name": "linux-server-next", "version_path": "Engine/Binaries/Linux/FactoryServerNext-Linux-Shipping.version", "target_name": "LinuxServer", "executables": ["FactoryServerNext.sh"]}
+	]}`), "test"))
+
+	_, err = installation.DetectPlatform(ctx)
+	testza.AssertNotNil(t, err, "the version file is still missing")
+
+	testza.AssertNoError(t, d.Write(filepath.Join("/server", "Engine", "Binaries", "Linux", "FactoryServerNext-Linux-Shipping.version"), []byte(`{"Changelist": 400000}`)))
+
+	detection, err := installation.DetectPlatform(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "linux-server-next", detection.Platform.Name)
+	testza.AssertEqual(t, []string{"FactoryServerNext.sh"}, detection.Executables)
+	testza.AssertEqual(t, "matched linux-server-next (test) by "+filepath.Join("Engine", "Binaries", "Linux", "FactoryServerNext-Linux-Shipping.version")+" and FactoryServerNext.sh", detection.Reason())
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -41,6 +40,7 @@ type Profiles struct {
 	Profiles        map[string]*Profile `json:"profiles"`
 	SelectedProfile string              `json:"selected_profile"`
 	Version         ProfilesVersion     `json:"version"`
+	loaded          stateHash
 }
 
 type Profile struct {
@@ -142,6 +142,11 @@ func InitProfiles() (*Profiles, error) {
 		return nil, fmt.Errorf("failed to read profiles: %w", err)
 	}
 
+	profilesData, err = migrateState(profilesFile, profilesData, profilesMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate profiles: %w", err)
+	}
+
 	var profiles Profiles
 	if err := json.Unmarshal(profilesData, &profiles); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal profiles: %w", err)
@@ -151,6 +156,8 @@ func InitProfiles() (*Profiles, error) {
 		return nil, fmt.Errorf("unknown profiles version: %d", profiles.Version)
 	}
 
+	profiles.loaded = hashState(profilesData)
+
 	if len(profiles.Profiles) == 0 {
 		profiles.Profiles = map[string]*Profile{
 			DefaultProfileName: &defaultProfile,
@@ -174,6 +181,16 @@ func (p *Profiles) Save() error {
 
 	profilesFile := filepath.Join(viper.GetString("local-dir"), viper.GetString("profiles-file"))
 
+	unlock, err := LockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := checkStateUnmodified(profilesFile, p.loaded); err != nil {
+		return err
+	}
+
 	slog.Info("saving profiles", slog.String("path", profilesFile))
 
 	profilesJSON, err := json.MarshalIndent(p, "", "  ")
@@ -181,10 +198,12 @@ func (p *Profiles) Save() error {
 		return fmt.Errorf("failed to marshal profiles: %w", err)
 	}
 
-	if err := os.WriteFile(profilesFile, profilesJSON, 0o755); err != nil {
+	if err := utils.WriteFileAtomic(profilesFile, profilesJSON, 0o644); err != nil {
 		return fmt.Errorf("failed to write profiles: %w", err)
 	}
 
+	p.loaded = hashState(profilesJSON)
+
 	return nil
 }
 
@@ -221,6 +240,28 @@ func (p *Profiles) GetProfile(name string) *Profile {
 	return p.Profiles[name]
 }
 
+// CloneProfile creates a new profile with the given name as a copy of an existing profile.
+func (p *Profiles) CloneProfile(source string, target string) (*Profile, error) {
+	sourceProfile, ok := p.Profiles[source]
+	if !ok {
+		return nil, fmt.Errorf("profile with name %s does not exist", source)
+	}
+
+	if _, ok := p.Profiles[target]; ok {
+		return nil, fmt.Errorf("profile with name %s already exists", target)
+	}
+
+	cloned, err := utils.Copy(*sourceProfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy profile: %w", err)
+	}
+
+	cloned.Name = target
+	p.Profiles[target] = cloned
+
+	return cloned, nil
+}
+
 func (p *Profiles) RenameProfile(ctx *GlobalContext, oldName string, newName string) error {
 	if _, ok := p.Profiles[newName]; ok {
 		return fmt.Errorf("profile with name %s already exists", newName)
@@ -253,8 +294,8 @@ func (p *Profile) AddMod(reference string, version string) error {
 		p.Mods = make(map[string]ProfileMod)
 	}
 
-	if !utils.SemVerRegex.MatchString(version) {
-		return errors.New("invalid semver version")
+	if err := utils.ValidateVersionConstraint(version); err != nil {
+		return err
 	}
 
 	p.Mods[reference] = ProfileMod{
@@ -265,6 +306,24 @@ func (p *Profile) AddMod(reference string, version string) error {
 	return nil
 }
 
+// SetModVersion changes the version constraint of a mod already in the profile.
+func (p *Profile) SetModVersion(reference string, version string) error {
+	if !p.HasMod(reference) {
+		return fmt.Errorf("profile %s does not contain mod %s", p.Name, reference)
+	}
+
+	if err := utils.ValidateVersionConstraint(version); err != nil {
+		return err
+	}
+
+	p.Mods[reference] = ProfileMod{
+		Version: version,
+		Enabled: p.Mods[reference].Enabled,
+	}
+
+	return nil
+}
+
 // RemoveMod removes a mod from the profile.
 func (p *Profile) RemoveMod(reference string) {
 	if p.Mods == nil {
@@ -294,7 +353,7 @@ func (p *Profile) Resolve(resolver resolver.DependencyResolver, lockFile *resolv
 	toResolve := make(map[string]string)
 	for modReference, mod := range p.Mods {
 		if mod.Enabled {
-			toResolve[modReference] = mod.Version
+			toResolve[modReference] = utils.NormalizeVersionConstraint(mod.Version)
 		}
 	}
 
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"sort"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+)
+
+type ModDiffType string
+
+var (
+	ModDiffTypeAdded   ModDiffType = "added"
+	ModDiffTypeRemoved ModDiffType = "removed"
+	ModDiffTypeChanged ModDiffType = "changed"
+)
+
+type ModDiff struct {
+	Reference string
+	Type      ModDiffType
+	From      string
+	To        string
+}
+
+// DiffProfiles compares the mod constraints of two profiles.
+//
+// Disabled mods are reported with a "(disabled)" suffix on their constraint.
+func DiffProfiles(from *Profile, to *Profile) []ModDiff {
+	return diffMaps(profileConstraints(from), profileConstraints(to))
+}
+
+// DiffLockFiles compares the resolved mod versions of two lockfiles.
+func DiffLockFiles(from *resolver.LockFile, to *resolver.LockFile) []ModDiff {
+	return diffMaps(lockFileVersions(from), lockFileVersions(to))
+}
+
+func profileConstraints(profile *Profile) map[string]string {
+	constraints := make(map[string]string)
+	if profile == nil {
+		return constraints
+	}
+
+	for reference, mod := range profile.Mods {
+		if mod.Enabled {
+			constraints[reference] = mod.Version
+		} else {
+			constraints[reference] = mod.Version + " (disabled)"
+		}
+	}
+
+	return constraints
+}
+
+func lockFileVersions(lockFile *resolver.LockFile) map[string]string {
+	versions := make(map[string]string)
+	if lockFile == nil {
+		return versions
+	}
+
+	for reference, mod := range lockFile.Mods {
+		versions[reference] = mod.Version
+	}
+
+	return versions
+}
+
+func diffMaps(from map[string]string, to map[string]string) []ModDiff {
+	diff := make([]ModDiff, 0)
+
+	for reference, fromVersion := range from {
+		toVersion, ok := to[reference]
+		if !ok {
+			diff = append(diff, ModDiff{
+				Reference: reference,
+				Type:      ModDiffTypeRemoved,
+				From:      fromVersion,
+			})
+			continue
+		}
+
+		if fromVersion != toVersion {
+			diff = append(diff, ModDiff{
+				Reference: reference,
+				Type:      ModDiffTypeChanged,
+				From:      fromVersion,
+				To:        toVersion,
+			})
+		}
+	}
+
+	for reference, toVersion := range to {
+		if _, ok := from[reference]; !ok {
+			diff = append(diff, ModDiff{
+				Reference: reference,
+				Type:      ModDiffTypeAdded,
+				To:        toVersion,
+			})
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Reference < diff[j].Reference
+	})
+
+	return diff
+}
+
+// String returns a single line human-readable representation of the difference.
+func (d ModDiff) String() string {
+	switch d.Type {
+	case ModDiffTypeAdded:
+		return "+ " + d.Reference + " " + d.To
+	case ModDiffTypeRemoved:
+		return "- " + d.Reference + " " + d.From
+	default:
+		return "~ " + d.Reference + " " + d.From + " -> " + d.To
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+	"github.com/satisfactorymodding/ficsit-cli/cli/savefile"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+type SaveModIssue struct {
+	Reference string
+	Version   string
+	Reason    string
+}
+
+// AddProfileFromSave creates a new profile pinned to the mod versions recorded in a save header.
+//
+// Mods that no longer exist are skipped, mods whose recorded version is no longer available
+// are added with a minimum version constraint instead. Both are reported as issues.
+func (p *Profiles) AddProfileFromSave(ctx context.Context, modProvider provider.Provider, name string, header *savefile.Header) (*Profile, []SaveModIssue, error) {
+	if !header.IsModdedSave || len(header.Mods()) == 0 {
+		return nil, nil, errors.New("save does not contain any mods")
+	}
+
+	if _, ok := p.Profiles[name]; ok {
+		return nil, nil, fmt.Errorf("profile with name %s already exists", name)
+	}
+
+	profile := &Profile{
+		Name: name,
+		Mods: make(map[string]ProfileMod),
+	}
+
+	issues := make([]SaveModIssue, 0)
+
+	for _, mod := range header.Mods() {
+		// Explicitly ignore bootstrapper
+		if strings.ToLower(mod.Reference) == "bootstrapper" {
+			continue
+		}
+
+		exists, err := modExists(ctx, modProvider, mod.Reference)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !exists {
+			issues = append(issues, SaveModIssue{
+				Reference: mod.Reference,
+				Version:   mod.Version,
+				Reason:    "mod no longer exists",
+			})
+			continue
+		}
+
+		version, available, err := providerVersion(ctx, modProvider, mod.Reference, mod.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !available {
+			issues = append(issues, SaveModIssue{
+				Reference: mod.Reference,
+				Version:   mod.Version,
+				Reason:    "version no longer available, using " + version,
+			})
+		}
+
+		if err := profile.AddMod(mod.Reference, version); err != nil {
+			return nil, nil, fmt.Errorf("failed to add %s: %w", mod.Reference, err)
+		}
+	}
+
+	p.Profiles[name] = profile
+
+	return profile, issues, nil
+}
+
+func modExists(ctx context.Context, modProvider provider.Provider, reference string) (bool, error) {
+	response, err := modProvider.Mods(ctx, ficsit.ModFilter{
+		References: []string{reference},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up mod %s: %w", reference, err)
+	}
+
+	for _, mod := range response.Mods.Mods {
+		if mod.Mod_reference == reference {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// providerVersion returns the version constraint pinning the mod to the provided version.
+//
+// If the provider no longer offers that version, a minimum version constraint is returned instead.
+func providerVersion(ctx context.Context, modProvider provider.Provider, reference string, version string) (string, bool, error) {
+	versions, err := modProvider.ModVersionsWithDependencies(ctx, reference)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch versions of %s: %w", reference, err)
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return version, true, nil
+		}
+	}
+
+	return ">=" + version, false, nil
+}
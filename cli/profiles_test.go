@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"context"
 	"testing"
 
 	"github.com/MarvinJWendt/testza"
 
 	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/cli/savefile"
 )
 
 func init() {
@@ -17,3 +19,74 @@ func TestProfilesInit(t *testing.T) {
 	testza.AssertNoError(t, err)
 	testza.AssertNotNil(t, profiles)
 }
+
+func TestCloneAndDiffProfiles(t *testing.T) {
+	profiles := &Profiles{
+		Profiles: map[string]*Profile{},
+	}
+
+	source, err := profiles.AddProfile("Source")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, source.AddMod("AreaActions", "1.6.5"))
+	testza.AssertNoError(t, source.AddMod("RefinedPower", ">=3.2.0"))
+
+	cloned, err := profiles.CloneProfile("Source", "Cloned")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Cloned", cloned.Name)
+	testza.AssertLen(t, DiffProfiles(source, cloned), 0)
+
+	_, err = profiles.CloneProfile("Source", "Cloned")
+	testza.AssertNotNil(t, err)
+
+	cloned.RemoveMod("RefinedPower")
+	testza.AssertNoError(t, cloned.AddMod("AreaActions", "1.6.7"))
+	testza.AssertNoError(t, cloned.AddMod("FicsitRemoteMonitoring", "0.10.1"))
+
+	testza.AssertEqual(t, []ModDiff{
+		{Reference: "AreaActions", Type: ModDiffTypeChanged, From: "1.6.5", To: "1.6.7"},
+		{Reference: "FicsitRemoteMonitoring", Type: ModDiffTypeAdded, To: "0.10.1"},
+		{Reference: "RefinedPower", Type: ModDiffTypeRemoved, From: ">=3.2.0"},
+	}, DiffProfiles(source, cloned))
+}
+
+func TestResolveModReference(t *testing.T) {
+	reference, err := ResolveModReference(context.Background(), MockProvider{}, "AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "AreaActions", reference)
+
+	reference, err = ResolveModReference(context.Background(), MockProvider{}, "refined power")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "RefinedPower", reference)
+
+	_, err = ResolveModReference(context.Background(), MockProvider{}, "Refined")
+	testza.AssertNotNil(t, err)
+}
+
+func TestAddProfileFromSave(t *testing.T) {
+	profiles := &Profiles{
+		Profiles: map[string]*Profile{},
+	}
+
+	header := &savefile.Header{
+		IsModdedSave: true,
+		ModMetadata: &savefile.ModMetadata{
+			Mods: []savefile.Mod{
+				{Reference: "AreaActions", Version: "1.6.7"},
+				{Reference: "FicsitRemoteMonitoring", Version: "0.9.0"},
+				{Reference: "DeletedMod", Version: "1.0.0"},
+			},
+		},
+	}
+
+	profile, issues, err := profiles.AddProfileFromSave(context.Background(), MockProvider{}, "FromSave", header)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, map[string]ProfileMod{
+		"AreaActions":            {Version: "1.6.7", Enabled: true},
+		"FicsitRemoteMonitoring": {Version: ">=0.9.0", Enabled: true},
+	}, profile.Mods)
+	testza.AssertLen(t, issues, 2)
+	testza.AssertNotNil(t, profiles.GetProfile("FromSave"))
+
+	_, _, err = profiles.AddProfileFromSave(context.Background(), MockProvider{}, "Vanilla", &savefile.Header{})
+	testza.AssertNotNil(t, err)
+}
@@ -0,0 +1,258 @@
+package savefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf16"
+)
+
+// Header versions introducing new fields
+const (
+	headerVersionSessionVisibility   = 5
+	headerVersionEditorObjectVersion = 7
+	headerVersionModMetadata         = 8
+	headerVersionSaveIdentifier      = 10
+	headerVersionPartitionedWorld    = 11
+	headerVersionSaveDataHash        = 12
+	headerVersionCreativeMode        = 13
+	headerVersionSaveName            = 14
+)
+
+// maxStringLength guards against allocating huge buffers when reading a corrupted or non-save file
+const maxStringLength = 16 * 1024 * 1024
+
+// unixEpochTicks is the amount of 100ns ticks between 0001-01-01 and 1970-01-01
+const unixEpochTicks = 621355968000000000
+
+type Header struct {
+	ModMetadata           *ModMetadata
+	SaveDateTime          time.Time
+	SaveName              string
+	MapName               string
+	MapOptions            string
+	SessionName           string
+	SaveIdentifier        string
+	RawModMetadata        string
+	HeaderVersion         int32
+	SaveVersion           int32
+	BuildVersion          int32
+	PlayedSeconds         int32
+	EditorObjectVersion   int32
+	SessionVisibility     uint8
+	IsModdedSave          bool
+	IsPartitionedWorld    bool
+	IsCreativeModeEnabled bool
+}
+
+type ModMetadata struct {
+	FullMapName string `json:"FullMapName"`
+	Mods        []Mod  `json:"Mods"`
+	Version     int    `json:"Version"`
+}
+
+type Mod struct {
+	Reference string `json:"Reference"`
+	Name      string `json:"Name"`
+	Version   string `json:"Version"`
+}
+
+// ReadHeaderFile reads the header of the save file at the provided path.
+func ReadHeaderFile(path string) (*Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open save file: %w", err)
+	}
+
+	defer f.Close()
+
+	return ReadHeader(bufio.NewReader(f))
+}
+
+// ReadHeader reads a save header from the provided reader.
+//
+// Only the header is consumed, the compressed save body that follows is left untouched.
+func ReadHeader(r io.Reader) (*Header, error) {
+	reader := &headerReader{r: r}
+	header := &Header{}
+
+	header.HeaderVersion = reader.int32()
+	header.SaveVersion = reader.int32()
+	header.BuildVersion = reader.int32()
+
+	if reader.err != nil {
+		return nil, fmt.Errorf("failed to read save header: %w", reader.err)
+	}
+
+	if header.HeaderVersion < 0 || header.HeaderVersion > headerVersionSaveName {
+		return nil, fmt.Errorf("unsupported save header version: %d", header.HeaderVersion)
+	}
+
+	if header.HeaderVersion >= headerVersionSaveName {
+		header.SaveName = reader.string()
+	}
+
+	header.MapName = reader.string()
+	header.MapOptions = reader.string()
+	header.SessionName = reader.string()
+	header.PlayedSeconds = reader.int32()
+	header.SaveDateTime = ticksToTime(reader.int64())
+
+	if header.HeaderVersion >= headerVersionSessionVisibility {
+		header.SessionVisibility = reader.uint8()
+	}
+
+	if header.HeaderVersion >= headerVersionEditorObjectVersion {
+		header.EditorObjectVersion = reader.int32()
+	}
+
+	if header.HeaderVersion >= headerVersionModMetadata {
+		header.RawModMetadata = reader.string()
+		header.IsModdedSave = reader.int32() != 0
+	}
+
+	if header.HeaderVersion >= headerVersionSaveIdentifier {
+		header.SaveIdentifier = reader.string()
+	}
+
+	if header.HeaderVersion >= headerVersionPartitionedWorld {
+		header.IsPartitionedWorld = reader.int32() != 0
+	}
+
+	if header.HeaderVersion >= headerVersionSaveDataHash {
+		// FMD5Hash: a bool flag followed by the 16 byte hash
+		reader.skip(4 + 16)
+	}
+
+	if header.HeaderVersion >= headerVersionCreativeMode {
+		header.IsCreativeModeEnabled = reader.int32() != 0
+	}
+
+	if reader.err != nil {
+		return nil, fmt.Errorf("failed to read save header: %w", reader.err)
+	}
+
+	if header.RawModMetadata != "" {
+		var metadata ModMetadata
+		if err := json.Unmarshal([]byte(header.RawModMetadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse mod metadata: %w", err)
+		}
+		header.ModMetadata = &metadata
+	}
+
+	return header, nil
+}
+
+// Mods returns the mods the save was last saved with.
+func (h *Header) Mods() []Mod {
+	if h.ModMetadata == nil {
+		return nil
+	}
+	return h.ModMetadata.Mods
+}
+
+func ticksToTime(ticks int64) time.Time {
+	if ticks == 0 {
+		return time.Time{}
+	}
+
+	ticks -= unixEpochTicks
+	return time.Unix(ticks/10_000_000, (ticks%10_000_000)*100).UTC()
+}
+
+// headerReader reads little endian Unreal primitives, retaining the first error encountered.
+type headerReader struct {
+	r   io.Reader
+	err error
+}
+
+func (h *headerReader) read(data any) {
+	if h.err != nil {
+		return
+	}
+
+	if err := binary.Read(h.r, binary.LittleEndian, data); err != nil {
+		h.err = err
+	}
+}
+
+func (h *headerReader) int32() int32 {
+	var v int32
+	h.read(&v)
+	return v
+}
+
+func (h *headerReader) int64() int64 {
+	var v int64
+	h.read(&v)
+	return v
+}
+
+func (h *headerReader) uint8() uint8 {
+	var v uint8
+	h.read(&v)
+	return v
+}
+
+func (h *headerReader) skip(n int64) {
+	if h.err != nil {
+		return
+	}
+
+	if _, err := io.CopyN(io.Discard, h.r, n); err != nil {
+		h.err = err
+	}
+}
+
+// string reads an FString.
+//
+// A positive length denotes a null terminated Latin-1 string,
+// a negative length denotes a null terminated UTF-16 string of -length characters.
+func (h *headerReader) string() string {
+	length := h.int32()
+	if h.err != nil || length == 0 {
+		return ""
+	}
+
+	if length > maxStringLength || length < -maxStringLength {
+		h.err = fmt.Errorf("string length %d exceeds limit", length)
+		return ""
+	}
+
+	if length > 0 {
+		data := make([]byte, length)
+		h.read(data)
+		if h.err != nil {
+			return ""
+		}
+
+		if data[len(data)-1] != 0 {
+			h.err = errors.New("string is not null terminated")
+			return ""
+		}
+
+		runes := make([]rune, len(data)-1)
+		for i, b := range data[:len(data)-1] {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+
+	data := make([]uint16, -length)
+	h.read(data)
+	if h.err != nil {
+		return ""
+	}
+
+	if data[len(data)-1] != 0 {
+		h.err = errors.New("string is not null terminated")
+		return ""
+	}
+
+	return string(utf16.Decode(data[:len(data)-1]))
+}
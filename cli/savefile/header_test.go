@@ -0,0 +1,133 @@
+package savefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+type fixtureWriter struct {
+	bytes.Buffer
+}
+
+func (f *fixtureWriter) int32(v int32) {
+	_ = binary.Write(f, binary.LittleEndian, v)
+}
+
+func (f *fixtureWriter) int64(v int64) {
+	_ = binary.Write(f, binary.LittleEndian, v)
+}
+
+func (f *fixtureWriter) string(s string) {
+	if s == "" {
+		f.int32(0)
+		return
+	}
+
+	for _, r := range s {
+		if r > 0xFF {
+			encoded := append(utf16.Encode([]rune(s)), 0)
+			f.int32(-int32(len(encoded)))
+			_ = binary.Write(f, binary.LittleEndian, encoded)
+			return
+		}
+	}
+
+	f.int32(int32(len(s) + 1))
+	f.WriteString(s)
+	f.WriteByte(0)
+}
+
+const modMetadataFixture = `{"Version":1,"FullMapName":"/Game/FactoryGame/Map/GameLevel01/Persistent_Level","Mods":[{"Reference":"SML","Name":"Satisfactory Mod Loader","Version":"3.6.1"},{"Reference":"AreaActions","Name":"Area Actions","Version":"1.6.7"}]}`
+
+func moddedHeaderFixture(headerVersion int32, sessionName string) []byte {
+	f := &fixtureWriter{}
+	f.int32(headerVersion)
+	f.int32(42)
+	f.int32(264901)
+	if headerVersion >= headerVersionSaveName {
+		f.string("Modded Save")
+	}
+	f.string("Persistent_Level")
+	f.string("?startloc=Grass Fields")
+	f.string(sessionName)
+	f.int32(3600)
+	f.int64(unixEpochTicks + 1_700_000_000*10_000_000)
+	f.WriteByte(1)
+	f.int32(41)
+	f.string(modMetadataFixture)
+	f.int32(1)
+	f.string("e7a1c3")
+	if headerVersion >= headerVersionPartitionedWorld {
+		f.int32(1)
+	}
+	if headerVersion >= headerVersionSaveDataHash {
+		f.int32(1)
+		f.Write(make([]byte, 16))
+	}
+	if headerVersion >= headerVersionCreativeMode {
+		f.int32(0)
+	}
+
+	// Start of the compressed body, must not be consumed
+	f.WriteString("body")
+
+	return f.Bytes()
+}
+
+func TestReadModdedHeader(t *testing.T) {
+	for _, headerVersion := range []int32{10, 13, 14} {
+		header, err := ReadHeader(bytes.NewReader(moddedHeaderFixture(headerVersion, "Factory")))
+		testza.AssertNoError(t, err)
+
+		testza.AssertEqual(t, headerVersion, header.HeaderVersion)
+		testza.AssertEqual(t, "Factory", header.SessionName)
+		testza.AssertEqual(t, "Persistent_Level", header.MapName)
+		testza.AssertEqual(t, int32(3600), header.PlayedSeconds)
+		testza.AssertEqual(t, time.Unix(1_700_000_000, 0).UTC(), header.SaveDateTime)
+		testza.AssertTrue(t, header.IsModdedSave)
+		testza.AssertEqual(t, "e7a1c3", header.SaveIdentifier)
+		testza.AssertEqual(t, []Mod{
+			{Reference: "SML", Name: "Satisfactory Mod Loader", Version: "3.6.1"},
+			{Reference: "AreaActions", Name: "Area Actions", Version: "1.6.7"},
+		}, header.Mods())
+	}
+}
+
+func TestReadUnicodeSessionName(t *testing.T) {
+	header, err := ReadHeader(bytes.NewReader(moddedHeaderFixture(13, "Fabrik ⚙")))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Fabrik ⚙", header.SessionName)
+}
+
+func TestReadVanillaHeader(t *testing.T) {
+	f := &fixtureWriter{}
+	f.int32(6)
+	f.int32(25)
+	f.int32(152331)
+	f.string("Persistent_Level")
+	f.string("")
+	f.string("Vanilla")
+	f.int32(60)
+	f.int64(0)
+	f.WriteByte(0)
+
+	header, err := ReadHeader(bytes.NewReader(f.Bytes()))
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, header.IsModdedSave)
+	testza.AssertNil(t, header.ModMetadata)
+	testza.AssertLen(t, header.Mods(), 0)
+}
+
+func TestReadInvalidHeader(t *testing.T) {
+	_, err := ReadHeader(bytes.NewReader([]byte("not a save file at all")))
+	testza.AssertNotNil(t, err)
+
+	truncated := moddedHeaderFixture(13, "Factory")[:40]
+	_, err = ReadHeader(bytes.NewReader(truncated))
+	testza.AssertNotNil(t, err)
+}
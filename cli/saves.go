@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// saveBackupTimeFormat names backups, with sub-second precision so backups of the same second do not collide
+const saveBackupTimeFormat = "20060102-150405.000000"
+
+type SaveBackup struct {
+	Time  time.Time
+	ID    string
+	Path  string
+	Files int
+	Size  int64
+}
+
+// SaveDirectory returns the directory containing the save games of this installation.
+//
+// The configured save path is used if set, otherwise the default location is derived from the platform.
+// Remote installations only have a default for Linux servers, relative to the login directory.
+func (i *Installation) SaveDirectory(ctx *GlobalContext) (string, error) {
+	if i.SavePath != "" {
+		return i.SavePath, nil
+	}
+
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if i.IsRemote() {
+		if platform.TargetName == "LinuxServer" && strings.HasPrefix(i.Path, "sftp://") {
+			return filepath.Join(".config", "Epic", "FactoryGame", "Saved", "SaveGames"), nil
+		}
+
+		return "", errors.New("save path must be configured for this remote installation")
+	}
+
+	if platform.TargetName == "LinuxServer" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+
+		return filepath.Join(home, ".config", "Epic", "FactoryGame", "Saved", "SaveGames"), nil
+	}
+
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return "", errors.New("could not determine save directory, LOCALAPPDATA is not set")
+	}
+
+	return filepath.Join(localAppData, "FactoryGame", "Saved", "SaveGames"), nil
+}
+
+func (i *Installation) saveBackupDirectory() string {
+	// The path may contain credentials, so it is hashed instead of used verbatim
+	pathHash := sha256.Sum256([]byte(i.Path))
+	return filepath.Join(viper.GetString("local-dir"), "save-backups", hex.EncodeToString(pathHash[:])[:16])
+}
+
+// BackupSaves copies the save directory of this installation into a new timestamped backup under local-dir.
+//
+// Old backups exceeding the save-backup-retention setting are removed afterwards.
+func (i *Installation) BackupSaves(ctx *GlobalContext) (*SaveBackup, error) {
+	saveDirectory, err := i.SaveDirectory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := d.Exists(saveDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed checking save directory: %w", err)
+	}
+
+	if !exists {
+		slog.Info("no save directory found, skipping backup", slog.String("path", saveDirectory))
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(i.saveBackupDirectory(), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	backup := &SaveBackup{
+		Time: time.Now(),
+	}
+
+	// Creating the directory claims the ID, another backup at the same time moves on to the next one
+	for {
+		backup.ID = backup.Time.Format(saveBackupTimeFormat)
+		backup.Path = filepath.Join(i.saveBackupDirectory(), backup.ID)
+
+		err := os.Mkdir(backup.Path, 0o755)
+		if err == nil {
+			break
+		}
+
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+
+		backup.Time = backup.Time.Add(time.Microsecond)
+	}
+
+	slog.Info("backing up saves", slog.String("from", saveDirectory), slog.String("to", backup.Path))
+
+	if err := copyFromDisk(d, saveDirectory, backup.Path, backup); err != nil {
+		_ = os.RemoveAll(backup.Path)
+		return nil, fmt.Errorf("failed to back up saves: %w", err)
+	}
+
+	if err := i.pruneSaveBackups(viper.GetInt("save-backup-retention")); err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// SaveBackups returns all save backups of this installation, newest first.
+func (i *Installation) SaveBackups() ([]SaveBackup, error) {
+	dir, err := os.ReadDir(i.saveBackupDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := make([]SaveBackup, 0, len(dir))
+	for _, entry := range dir {
+		if !entry.IsDir() {
+			continue
+		}
+
+		backupTime, err := time.ParseInLocation(saveBackupTimeFormat, entry.Name(), time.Local)
+		if err != nil {
+			continue
+		}
+
+		backup := SaveBackup{
+			ID:   entry.Name(),
+			Path: filepath.Join(i.saveBackupDirectory(), entry.Name()),
+			Time: backupTime,
+		}
+
+		err = filepath.WalkDir(backup.Path, func(_ string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err //nolint:wrapcheck
+				}
+				backup.Files++
+				backup.Size += info.Size()
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", entry.Name(), err)
+		}
+
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(a, b int) bool {
+		return backups[a].Time.After(backups[b].Time)
+	})
+
+	return backups, nil
+}
+
+// RestoreSaves copies the files of the backup with the given ID back into the save directory.
+//
+// Files in the save directory that are not part of the backup are left untouched.
+func (i *Installation) RestoreSaves(ctx *GlobalContext, id string) error {
+	backupPath := filepath.Join(i.saveBackupDirectory(), filepath.Base(id))
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("backup %s not found: %w", id, err)
+	}
+
+	saveDirectory, err := i.SaveDirectory(ctx)
+	if err != nil {
+		return err
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("restoring saves", slog.String("from", backupPath), slog.String("to", saveDirectory))
+
+	return copyToDisk(backupPath, d, saveDirectory)
+}
+
+func (i *Installation) pruneSaveBackups(retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	backups, err := i.SaveBackups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) <= retention {
+		return nil
+	}
+
+	for _, backup := range backups[retention:] {
+		slog.Info("removing old save backup", slog.String("path", backup.Path))
+		if err := os.RemoveAll(backup.Path); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func copyFromDisk(d disk.Disk, source string, target string, backup *SaveBackup) error {
+	entries, err := d.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", source, err)
+	}
+
+	for _, entry := range entries {
+		sourcePath := filepath.Join(source, entry.Name())
+		targetPath := filepath.Join(target, entry.Name())
+
+		if entry.IsDir() {
+			if err := os.MkdirAll(targetPath, 0o755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
+			}
+
+			if err := copyFromDisk(d, sourcePath, targetPath, backup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := d.Read(sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", sourcePath, err)
+		}
+
+		if err := os.WriteFile(targetPath, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", targetPath, err)
+		}
+
+		backup.Files++
+		backup.Size += int64(len(data))
+	}
+
+	return nil
+}
+
+func copyToDisk(source string, d disk.Disk, target string) error {
+	if err := d.MkDir(target); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", target, err)
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", source, err)
+	}
+
+	for _, entry := range entries {
+		sourcePath := filepath.Join(source, entry.Name())
+		targetPath := filepath.Join(target, entry.Name())
+
+		if entry.IsDir() {
+			if err := copyToDisk(sourcePath, d, targetPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", sourcePath, err)
+		}
+
+		if err := d.Write(targetPath, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", targetPath, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+)
+
+func init() {
+	cfg.SetDefaults()
+}
+
+func TestBackupAndRestoreSaves(t *testing.T) {
+	saveDir := t.TempDir()
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(saveDir, "server"), 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(saveDir, "server", "Factory.sav"), []byte("original"), 0o644))
+
+	installation := &Installation{
+		Path:     filepath.Join(t.TempDir(), "BackupTest"),
+		SavePath: saveDir,
+	}
+
+	t.Cleanup(func() {
+		_ = os.RemoveAll(installation.saveBackupDirectory())
+	})
+
+	backup, err := installation.BackupSaves(nil)
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, backup)
+	testza.AssertEqual(t, 1, backup.Files)
+
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(saveDir, "server", "Factory.sav"), []byte("broken"), 0o644))
+
+	backups, err := installation.SaveBackups()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, backups, 1)
+	testza.AssertEqual(t, backup.ID, backups[0].ID)
+
+	testza.AssertNoError(t, installation.RestoreSaves(nil, backup.ID))
+
+	data, err := os.ReadFile(filepath.Join(saveDir, "server", "Factory.sav"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "original", string(data))
+
+	viper.Set("save-backup-retention", 1)
+	defer viper.Set("save-backup-retention", 10)
+
+	_, err = installation.BackupSaves(nil)
+	testza.AssertNoError(t, err)
+
+	backups, err = installation.SaveBackups()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, backups, 1)
+	testza.AssertNotEqual(t, backup.ID, backups[0].ID)
+}
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// quarantineTimeFormat names quarantine directories, with sub-second precision so quarantines of the same second do not collide
+const quarantineTimeFormat = "20060102-150405.000000"
+
+// UnmanagedMod is a mod directory that was not installed by ficsit
+type UnmanagedMod struct {
+	ModReference string
+	Version      string
+	FriendlyName string
+	// Duplicate is the reference of the resolved mod this one clashes with
+	Duplicate string
+	// Issue describes why the mod could not be read
+	Issue string
+}
+
+type AdoptModIssue struct {
+	Reference string
+	Version   string
+	Reason    string
+}
+
+// ScanMods returns every directory in the Mods directory that has no .smm file
+func (i *Installation) ScanMods(ctx *GlobalContext) ([]UnmanagedMod, error) {
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	lockfile, err := i.lockfile(ctx, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	managed := make([]string, 0)
+	if lockfile != nil {
+		for modReference := range lockfile.Mods {
+			managed = append(managed, modReference)
+		}
+	}
+
+	if profile := ctx.Profiles.GetProfile(i.Profile); profile != nil {
+		for modReference := range profile.Mods {
+			managed = append(managed, modReference)
+		}
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	exists, err := d.Exists(modsDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return []UnmanagedMod{}, nil
+	}
+
+	dir, err := d.ReadDir(modsDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mods directory: %w", err)
+	}
+
+	mods := make([]UnmanagedMod, 0)
+	for _, entry := range dir {
+		if !entry.IsDir() {
+			continue
+		}
+
+		modDir := filepath.Join(modsDirectory, entry.Name())
+
+		exists, err := d.Exists(filepath.Join(modDir, ".smm"))
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			continue
+		}
+
+		mod := readUnmanagedMod(d, modDir)
+
+		for _, modReference := range managed {
+			if strings.EqualFold(modReference, mod.ModReference) {
+				mod.Duplicate = modReference
+				break
+			}
+		}
+
+		mods = append(mods, mod)
+	}
+
+	sort.Slice(mods, func(a, b int) bool {
+		return mods[a].ModReference < mods[b].ModReference
+	})
+
+	return mods, nil
+}
+
+func readUnmanagedMod(d disk.Disk, modDir string) UnmanagedMod {
+	mod := UnmanagedMod{
+		ModReference: filepath.Base(modDir),
+	}
+
+	data, err := d.Read(filepath.Join(modDir, mod.ModReference+".uplugin"))
+	if err != nil {
+		mod.Issue = "no " + mod.ModReference + ".uplugin"
+		return mod
+	}
+
+	var uplugin cache.UPlugin
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &uplugin); err != nil {
+		mod.Issue = "invalid .uplugin: " + err.Error()
+		return mod
+	}
+
+	mod.Version = uplugin.SemVersion
+	mod.FriendlyName = uplugin.FriendlyName
+
+	if mod.Version == "" {
+		mod.Issue = ".uplugin has no SemVersion"
+	}
+
+	return mod
+}
+
+// AdoptMods adds the unmanaged mods to the installation's profile, pinned to their installed versions.
+//
+// Mods that can not be read, clash with a resolved mod or are unknown to the provider are skipped and reported as issues.
+// The adopted mods are replaced by managed copies on the next apply.
+func (i *Installation) AdoptMods(ctx *GlobalContext, mods []UnmanagedMod) ([]AdoptModIssue, error) {
+	profile := ctx.Profiles.GetProfile(i.Profile)
+	if profile == nil {
+		return nil, errors.New("could not find profile " + i.Profile)
+	}
+
+	issues := make([]AdoptModIssue, 0)
+
+	for _, mod := range mods {
+		issue := AdoptModIssue{
+			Reference: mod.ModReference,
+			Version:   mod.Version,
+		}
+
+		if mod.Issue != "" {
+			issue.Reason = mod.Issue
+			issues = append(issues, issue)
+			continue
+		}
+
+		if mod.Duplicate != "" {
+			issue.Reason = "duplicate of resolved mod " + mod.Duplicate + ", quarantine it instead"
+			issues = append(issues, issue)
+			continue
+		}
+
+		exists, err := modExists(context.TODO(), ctx.Provider, mod.ModReference)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			issue.Reason = "mod not found"
+			issues = append(issues, issue)
+			continue
+		}
+
+		version, available, err := providerVersion(context.TODO(), ctx.Provider, mod.ModReference, mod.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		if !available {
+			issue.Reason = "version no longer available, using " + version
+			issues = append(issues, issue)
+		}
+
+		if err := profile.AddMod(mod.ModReference, version); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", mod.ModReference, err)
+		}
+
+		slog.Info("adopted mod", slog.String("mod_reference", mod.ModReference), slog.String("version", version))
+	}
+
+	return issues, nil
+}
+
+// QuarantineMods moves the unmanaged mods out of the Mods directory so the game no longer loads them.
+//
+// Returns the directory they were moved into.
+func (i *Installation) QuarantineMods(mods []UnmanagedMod) (string, error) {
+	d, err := i.GetDisk()
+	if err != nil {
+		return "", err
+	}
+
+	held, err := i.lock(d)
+	if err != nil {
+		return "", err
+	}
+	defer held.Release()
+
+	// Quarantines run under the install lock, so the first unused timestamp can not be taken concurrently
+	quarantineTime := time.Now()
+	quarantineDirectory := ""
+	for {
+		quarantineDirectory = filepath.Join(i.BasePath(), "FactoryGame", "ModsQuarantine", quarantineTime.Format(quarantineTimeFormat))
+
+		exists, err := d.Exists(quarantineDirectory)
+		if err != nil {
+			return "", fmt.Errorf("failed to check quarantine directory: %w", err)
+		}
+
+		if !exists {
+			break
+		}
+
+		quarantineTime = quarantineTime.Add(time.Microsecond)
+	}
+
+	if err := d.MkDir(quarantineDirectory); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	for _, mod := range mods {
+		if err := held.Lost(); err != nil {
+			return "", err
+		}
+
+		slog.Info("quarantining mod", slog.String("mod_reference", mod.ModReference), slog.String("path", quarantineDirectory))
+
+		if err := d.Rename(filepath.Join(modsDirectory, mod.ModReference), filepath.Join(quarantineDirectory, mod.ModReference)); err != nil {
+			return "", fmt.Errorf("failed to move %s: %w", mod.ModReference, err)
+		}
+	}
+
+	return quarantineDirectory, nil
+}
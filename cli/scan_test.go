@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestScanAdoptAndQuarantineMods(t *testing.T) {
+	installation, ctx := newTestServerInstallation(t, "Scan", `{"Changelist": 365306}`)
+	ctx.Provider = MockProvider{}
+	profile := ctx.Profiles.Profiles["Scan"]
+	testza.AssertNoError(t, profile.AddMod("RefinedPower", "3.2.10"))
+
+	d := installation.DiskInstance
+	mods := filepath.Join("/server", "FactoryGame", "Mods")
+
+	for name, uplugin := range map[string]string{
+		"AreaActions":            "\xef\xbb\xbf" + `{"SemVersion": "1.6.6", "FriendlyName": "Area Actions"}`,
+		"FicsitRemoteMonitoring": `{"SemVersion": "0.9.0"}`,
+		"refinedpower":           `{"SemVersion": "3.2.10"}`,
+		"Unknown":                `{"SemVersion": "1.0.0"}`,
+		"Broken":                 "",
+	} {
+		testza.AssertNoError(t, d.MkDir(filepath.Join(mods, name)))
+		if uplugin != "" {
+			testza.AssertNoError(t, d.Write(filepath.Join(mods, name, name+".uplugin"), []byte(uplugin)))
+		}
+	}
+
+	testza.AssertNoError(t, d.MkDir(filepath.Join(mods, "SML")))
+	testza.AssertNoError(t, d.Write(filepath.Join(mods, "SML", ".smm"), []byte("hash")))
+
+	unmanaged, err := installation.ScanMods(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []UnmanagedMod{
+		{ModReference: "AreaActions", Version: "1.6.6", FriendlyName: "Area Actions"},
+		{ModReference: "Broken", Issue: "no Broken.uplugin"},
+		{ModReference: "FicsitRemoteMonitoring", Version: "0.9.0"},
+		{ModReference: "Unknown", Version: "1.0.0"},
+		{ModReference: "refinedpower", Version: "3.2.10", Duplicate: "RefinedPower"},
+	}, unmanaged)
+
+	issues, err := installation.AdoptMods(ctx, unmanaged)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, issues, 4)
+	testza.AssertEqual(t, map[string]ProfileMod{
+		"AreaActions":            {Version: "1.6.6", Enabled: true},
+		"FicsitRemoteMonitoring": {Version: ">=0.9.0", Enabled: true},
+		"RefinedPower":           {Version: "3.2.10", Enabled: true},
+	}, profile.Mods)
+
+	directory, err := installation.QuarantineMods(unmanaged[4:])
+	testza.AssertNoError(t, err)
+
+	exists, err := d.Exists(filepath.Join(directory, "refinedpower", "refinedpower.uplugin"))
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	exists, err = d.Exists(filepath.Join(mods, "refinedpower"))
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+}
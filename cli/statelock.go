@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	stateLockFile = ".lock"
+
+	MutatesStateAnnotation = "mutates-state"
+)
+
+// MutatesState annotates commands that modify the state files.
+//
+// They hold the state lock for their whole run, so their changes can not interleave with other ficsit processes.
+var MutatesState = map[string]string{MutatesStateAnnotation: "true"}
+
+// ErrStateModified is returned when saving a state file that another process changed since it was loaded
+var ErrStateModified = errors.New("modified by another ficsit process, reload and try again")
+
+var ErrStateLocked = errors.New("state is locked by another ficsit process")
+
+var stateLock struct {
+	file  *os.File
+	count int
+	mux   sync.Mutex
+}
+
+// LockState acquires the advisory lock on the local directory, waiting up to lock-timeout for other processes to release it.
+//
+// The lock is reentrant within the process, every successful call must be paired with a call to the returned function.
+func LockState() (func(), error) {
+	stateLock.mux.Lock()
+	defer stateLock.mux.Unlock()
+
+	if stateLock.count > 0 {
+		stateLock.count++
+		return unlockState, nil
+	}
+
+	localDir := viper.GetString("local-dir")
+	if err := os.MkdirAll(localDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(localDir, stateLockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state lock: %w", err)
+	}
+
+	deadline := time.Now().Add(viper.GetDuration("lock-timeout"))
+	waiting := false
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock state: %w", err)
+		}
+
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, ErrStateLocked
+		}
+
+		if !waiting {
+			slog.Info("waiting for another ficsit process to release the state lock")
+			waiting = true
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	stateLock.file = f
+	stateLock.count = 1
+
+	return unlockState, nil
+}
+
+func unlockState() {
+	stateLock.mux.Lock()
+	defer stateLock.mux.Unlock()
+
+	if stateLock.count == 0 {
+		return
+	}
+
+	stateLock.count--
+	if stateLock.count > 0 {
+		return
+	}
+
+	if err := unlockFile(stateLock.file); err != nil {
+		slog.Warn("failed to unlock state", slog.Any("err", err))
+	}
+
+	_ = stateLock.file.Close()
+	stateLock.file = nil
+}
+
+// stateHash identifies the contents of a state file, to detect modifications by other processes
+type stateHash [sha256.Size]byte
+
+func hashState(data []byte) stateHash {
+	return sha256.Sum256(data)
+}
+
+// checkStateUnmodified returns ErrStateModified if the file no longer has the contents it had when it was loaded.
+//
+// Files that were never loaded or do not exist yet are not checked.
+func checkStateUnmodified(stateFile string, loaded stateHash) error {
+	if loaded == (stateHash{}) {
+		return nil
+	}
+
+	current, err := os.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", stateFile, err)
+	}
+
+	if hashState(current) != loaded {
+		return fmt.Errorf("%s was %w", filepath.Base(stateFile), ErrStateModified)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+)
+
+func TestSaveDetectsExternalModification(t *testing.T) {
+	path := useStateFixture(t, "profiles-v0.json", viper.GetString("profiles-file"))
+
+	profiles, err := InitProfiles()
+	testza.AssertNoError(t, err)
+
+	_, err = profiles.AddProfile("First")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, profiles.Save())
+
+	// Saving again after our own save is fine
+	testza.AssertNoError(t, profiles.Save())
+
+	other, err := InitProfiles()
+	testza.AssertNoError(t, err)
+	_, err = other.AddProfile("Other")
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, other.Save())
+
+	err = profiles.Save()
+	testza.AssertTrue(t, errors.Is(err, ErrStateModified), err)
+
+	reloaded, err := InitProfiles()
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, reloaded.GetProfile("Other"), "the external change is kept")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	testza.AssertNoError(t, err)
+	for _, entry := range entries {
+		testza.AssertFalse(t, filepath.Ext(entry.Name()) == ".tmp", "no temporary files are left behind")
+	}
+}
+
+func TestLockStateIsReentrant(t *testing.T) {
+	localDir := viper.GetString("local-dir")
+	viper.Set("local-dir", t.TempDir())
+	defer viper.Set("local-dir", localDir)
+
+	unlockOuter, err := LockState()
+	testza.AssertNoError(t, err)
+
+	unlockInner, err := LockState()
+	testza.AssertNoError(t, err)
+	unlockInner()
+
+	testza.AssertNotNil(t, stateLock.file, "the lock is held until the outermost unlock")
+
+	// A second open file description conflicts with the held lock, like another process would
+	f, err := os.OpenFile(filepath.Join(viper.GetString("local-dir"), stateLockFile), os.O_RDWR, 0o600)
+	testza.AssertNoError(t, err)
+	defer f.Close()
+
+	locked, err := tryLockFile(f)
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, locked)
+
+	unlockOuter()
+	testza.AssertNil(t, stateLock.file)
+
+	locked, err = tryLockFile(f)
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, locked)
+	testza.AssertNoError(t, unlockFile(f))
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// vdfNode is a node of Valve's KeyValues text format, used by Steam's .vdf and .acf files.
+//
+// Keys are case-insensitive, so children are stored with lowercase keys.
+type vdfNode struct {
+	Children map[string]*vdfNode
+	Value    string
+}
+
+// Get walks the given keys and returns the node at the end, or nil if any of them is missing
+func (n *vdfNode) Get(keys ...string) *vdfNode {
+	current := n
+	for _, key := range keys {
+		if current == nil || current.Children == nil {
+			return nil
+		}
+		current = current.Children[strings.ToLower(key)]
+	}
+	return current
+}
+
+// String returns the value at the given keys, or an empty string if it is missing
+func (n *vdfNode) String(keys ...string) string {
+	if node := n.Get(keys...); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+type vdfTokenKind int
+
+const (
+	vdfEOF vdfTokenKind = iota
+	vdfString
+	vdfOpen
+	vdfClose
+)
+
+type vdfScanner struct {
+	data []byte
+	pos  int
+}
+
+func parseVDF(data []byte) (*vdfNode, error) {
+	s := &vdfScanner{data: data}
+
+	root, err := s.parseObject(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vdf: %w", err)
+	}
+
+	return root, nil
+}
+
+func (s *vdfScanner) parseObject(nested bool) (*vdfNode, error) {
+	node := &vdfNode{Children: make(map[string]*vdfNode)}
+
+	for {
+		kind, key, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case vdfEOF:
+			if nested {
+				return nil, errors.New("unexpected end of file")
+			}
+			return node, nil
+		case vdfClose:
+			if !nested {
+				return nil, fmt.Errorf("unexpected } at offset %d", s.pos)
+			}
+			return node, nil
+		case vdfOpen:
+			return nil, fmt.Errorf("unexpected { at offset %d", s.pos)
+		}
+
+		kind, value, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case vdfString:
+			node.Children[strings.ToLower(key)] = &vdfNode{Value: value}
+		case vdfOpen:
+			child, err := s.parseObject(true)
+			if err != nil {
+				return nil, err
+			}
+			node.Children[strings.ToLower(key)] = child
+		default:
+			return nil, fmt.Errorf("missing value for %s", key)
+		}
+	}
+}
+
+func (s *vdfScanner) next() (vdfTokenKind, string, error) {
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			s.pos++
+		case c == '/' && s.pos+1 < len(s.data) && s.data[s.pos+1] == '/':
+			for s.pos < len(s.data) && s.data[s.pos] != '\n' {
+				s.pos++
+			}
+		case c == '[':
+			// Conditionals like [$WIN32] apply to the preceding entry and are ignored
+			for s.pos < len(s.data) && s.data[s.pos] != ']' {
+				s.pos++
+			}
+			s.pos++
+		case c == '{':
+			s.pos++
+			return vdfOpen, "", nil
+		case c == '}':
+			s.pos++
+			return vdfClose, "", nil
+		case c == '"':
+			return s.quoted()
+		default:
+			start := s.pos
+			for s.pos < len(s.data) && !strings.ContainsRune(" \t\r\n{}\"", rune(s.data[s.pos])) {
+				s.pos++
+			}
+			return vdfString, string(s.data[start:s.pos]), nil
+		}
+	}
+
+	return vdfEOF, "", nil
+}
+
+func (s *vdfScanner) quoted() (vdfTokenKind, string, error) {
+	var value strings.Builder
+
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		c := s.data[s.pos]
+
+		switch c {
+		case '"':
+			s.pos++
+			return vdfString, value.String(), nil
+		case '\\':
+			s.pos++
+			if s.pos >= len(s.data) {
+				break
+			}
+			switch s.data[s.pos] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(s.data[s.pos])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return vdfEOF, "", errors.New("unterminated string")
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+type ModStatus string
+
+const (
+	ModStatusOK         ModStatus = "ok"
+	ModStatusMissing    ModStatus = "missing"
+	ModStatusOutdated   ModStatus = "outdated"
+	ModStatusNoManifest ModStatus = "unverifiable, no manifest"
+	ModStatusDamaged    ModStatus = "damaged"
+)
+
+// Failed returns true if the mod files are known to not match the lockfile.
+//
+// Mods extracted before manifests were written can not be verified, but are not considered failed.
+func (s ModStatus) Failed() bool {
+	return s != ModStatusOK && s != ModStatusNoManifest
+}
+
+type ModVerification struct {
+	ModReference string
+	Version      string
+	Status       ModStatus
+	Issues       []utils.ModFileIssue
+}
+
+// Verify checks every mod of the installation's lockfile against the manifest written when it was extracted
+func (i *Installation) Verify(ctx *GlobalContext) ([]ModVerification, error) {
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	lockfile, err := i.lockfile(ctx, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	if lockfile == nil || i.Vanilla {
+		return []ModVerification{}, nil
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	results := make([]ModVerification, 0, len(lockfile.Mods))
+	for modReference, version := range lockfile.Mods {
+		target, ok := version.Targets[platform.TargetName]
+		if !ok || target.Link == "" {
+			continue
+		}
+
+		result := ModVerification{
+			ModReference: modReference,
+			Version:      version.Version,
+			Status:       ModStatusOK,
+		}
+
+		modDir := filepath.Join(modsDirectory, modReference)
+		hashFile := filepath.Join(modDir, ".smm")
+
+		exists, err := d.Exists(hashFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			result.Status = ModStatusMissing
+			results = append(results, result)
+			continue
+		}
+
+		hash, err := d.Read(hashFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .smm mod hash file: %w", err)
+		}
+
+		if string(hash) != target.Hash {
+			result.Status = ModStatusOutdated
+			results = append(results, result)
+			continue
+		}
+
+		issues, err := utils.VerifyMod(modDir, d)
+		if err != nil {
+			if !errors.Is(err, utils.ErrNoManifest) {
+				return nil, fmt.Errorf("failed to verify %s: %w", modReference, err)
+			}
+
+			result.Status = ModStatusNoManifest
+		} else if len(issues) > 0 {
+			result.Status = ModStatusDamaged
+			result.Issues = issues
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].ModReference < results[b].ModReference
+	})
+
+	return results, nil
+}
+
+// Repair re-extracts every mod that did not pass verification, using the download cache where possible
+func (i *Installation) Repair(ctx *GlobalContext, results []ModVerification) error {
+	platform, err := i.GetPlatform(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	lockfile, err := i.lockfile(ctx, platform)
+	if err != nil {
+		return fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	if lockfile == nil {
+		return nil
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	held, err := i.lock(d)
+	if err != nil {
+		return err
+	}
+	defer held.Release()
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+	if err := d.MkDir(modsDirectory); err != nil {
+		return fmt.Errorf("failed creating Mods directory: %w", err)
+	}
+
+	downloadSemaphore := make(chan int, viper.GetInt("concurrent-downloads"))
+	defer close(downloadSemaphore)
+
+	for _, result := range results {
+		if result.Status == ModStatusOK {
+			continue
+		}
+
+		if err := held.Lost(); err != nil {
+			return err
+		}
+
+		version, ok := lockfile.Mods[result.ModReference]
+		if !ok {
+			continue
+		}
+
+		target, ok := version.Targets[platform.TargetName]
+		if !ok {
+			continue
+		}
+
+		if result.Status == ModStatusNoManifest {
+			slog.Info("writing missing mod manifest", slog.String("mod_reference", result.ModReference), slog.String("version", version.Version))
+
+			if err := writeMissingManifest(result.ModReference, version.Version, target.Link, target.Hash, platform.TargetName, filepath.Join(modsDirectory, result.ModReference), downloadSemaphore, d); err != nil {
+				return fmt.Errorf("failed to write manifest of %s@%s: %w", result.ModReference, version.Version, err)
+			}
+
+			continue
+		}
+
+		slog.Info("repairing mod", slog.String("mod_reference", result.ModReference), slog.String("version", version.Version), slog.String("status", string(result.Status)))
+
+		// Without the hash file the mod is always extracted again
+		if err := d.Remove(filepath.Join(modsDirectory, result.ModReference, ".smm")); err != nil {
+			return fmt.Errorf("failed to remove .smm mod hash file: %w", err)
+		}
+
+		if err := downloadAndExtractMod(result.ModReference, version.Version, target.Link, target.Hash, platform.TargetName, modsDirectory, nil, downloadSemaphore, d); err != nil {
+			return fmt.Errorf("failed to repair %s@%s: %w", result.ModReference, version.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// writeMissingManifest writes the manifest of a mod extracted before manifests were written, without extracting it again
+func writeMissingManifest(modReference string, version string, link string, hash string, target string, modDirectory string, downloadSemaphore chan int, d disk.Disk) error {
+	reader, size, err := cache.DownloadOrCache(modReference+"_"+version+"_"+target+".zip", hash, link, nil, downloadSemaphore)
+	if err != nil {
+		return fmt.Errorf("failed to download %s from: %s: %w", modReference, link, err)
+	}
+
+	defer reader.Close()
+
+	manifest, err := utils.BuildModManifest(reader, size, hash)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return utils.WriteModManifest(modDirectory, manifest, d) //nolint:wrapcheck
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+func TestVerifyModWithoutManifest(t *testing.T) {
+	cacheDir := viper.GetString("cache-dir")
+	viper.Set("cache-dir", t.TempDir())
+	defer viper.Set("cache-dir", cacheDir)
+
+	_, err := cache.LoadCacheMods()
+	testza.AssertNoError(t, err)
+
+	archive, err := utils.BuildArchive(utils.ArchiveEntry{Name: "Example.uplugin", Content: "{}"})
+	testza.AssertNoError(t, err)
+
+	hash, err := utils.SHA256Data(bytes.NewReader(archive))
+	testza.AssertNoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	installation, ctx := newTestServerInstallation(t, "Verify", `{"Changelist": 365306}`)
+
+	d := installation.DiskInstance
+	modDir := filepath.Join("/server", "FactoryGame", "Mods", "Example")
+
+	// Installed before manifests were written
+	testza.AssertNoError(t, utils.ExtractMod(bytes.NewReader(archive), int64(len(archive)), modDir, hash, utils.DefaultArchiveLimits, nil, d))
+	testza.AssertNoError(t, d.Remove(filepath.Join(modDir, utils.ModManifestFile)))
+
+	testza.AssertNoError(t, installation.WriteLockFile(ctx, &resolver.LockFile{
+		Mods: map[string]resolver.LockedMod{
+			"Example": {
+				Version: "1.0.0",
+				Targets: map[string]resolver.LockedModTarget{
+					"LinuxServer": {Link: server.URL, Hash: hash},
+				},
+			},
+		},
+	}))
+
+	results, err := installation.Verify(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, results, 1)
+	testza.AssertEqual(t, ModStatusNoManifest, results[0].Status)
+	testza.AssertFalse(t, results[0].Status.Failed())
+
+	// The mod is not extracted again, only its manifest is recorded
+	testza.AssertNoError(t, d.Write(filepath.Join(modDir, "config.ini"), []byte("kept")))
+	testza.AssertNoError(t, installation.Repair(ctx, results))
+
+	config, err := d.Read(filepath.Join(modDir, "config.ini"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "kept", string(config))
+
+	results, err = installation.Verify(ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, ModStatusOK, results[0].Status)
+}
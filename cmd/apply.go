@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"log/slog"
 	"os"
 	"sync"
@@ -8,41 +9,45 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
 
+func init() {
+	applyCmd.Flags().StringSlice("tag", nil, "Only apply to installations with any of these tags")
+}
+
 var applyCmd = &cobra.Command{
 	Use:   "apply [installation] ...",
-	Short: "Apply profiles to all installations",
+	Short: "Apply profiles to all installations, or only the given ones",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		var wg sync.WaitGroup
-		errored := false
-		for _, installation := range global.Installations.Installations {
-			if len(args) > 0 {
-				found := false
-
-				for _, installPath := range args {
-					if installation.Path == installPath {
-						found = true
-						break
-					}
-				}
+		tags, _ := cmd.Flags().GetStringSlice("tag")
 
-				if !found {
-					continue
-				}
-			}
+		installations, err := global.Installations.SelectInstallations(args, tags)
+		if err != nil {
+			return err
+		}
 
+		var wg sync.WaitGroup
+		errored := false
+		for _, installation := range installations {
 			wg.Add(1)
 
 			go func(installation *cli.Installation) {
 				defer wg.Done()
 				if err := installation.Install(global, nil); err != nil {
 					errored = true
+
+					var archiveErr utils.ArchiveError
+					if errors.As(err, &archiveErr) {
+						slog.Error("refused to install unsafe mod archive", slog.String("path", installation.DisplayPath()), slog.String("mod_reference", archiveErr.Mod), slog.String("entry", archiveErr.Entry), slog.Any("reason", archiveErr.Err))
+						return
+					}
+
 					slog.Error("installation failed", slog.Any("err", err))
 				}
 			}(installation)
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 	"github.com/satisfactorymodding/ficsit-cli/tea"
 )
 
@@ -20,6 +25,17 @@ var cliCmd = &cobra.Command{
 			slog.String("commit", viper.GetString("commit")),
 		)
 
+		// The terminal is owned by the TUI once it starts, so an existing credential store is unlocked before
+		if _, err := os.Stat(credentials.Path()); err == nil {
+			if _, err := credentials.Default(); err != nil && !errors.Is(err, credentials.ErrLocked) {
+				return fmt.Errorf("failed to unlock credential store: %w", err)
+			}
+		}
+
+		// Authentication can not prompt on the terminal while the TUI runs
+		disk.SetPrompter(nil)
+		credentials.SetPassphrasePrompt(nil)
+
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
@@ -0,0 +1,39 @@
+package credential
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:         "import",
+	Short:       "Move passwords embedded in installation urls into the credential store",
+	Annotations: cli.MutatesState,
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		changed, err := global.Installations.ExtractCredentials()
+		if err != nil {
+			return err
+		}
+
+		if changed > 0 {
+			if err := global.Installations.Save(); err != nil {
+				return err
+			}
+		}
+
+		println("Moved credentials of", changed, "installation(s) into the credential store")
+
+		return nil
+	},
+}
@@ -0,0 +1,30 @@
+package credential
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+func init() {
+	Cmd.AddCommand(lsCmd)
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List stored credential ids",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := credentials.Default()
+		if err != nil {
+			return err
+		}
+
+		for _, id := range store.IDs() {
+			credential, _ := store.Get(id)
+			println(id, "-", credential.Username)
+		}
+
+		return nil
+	},
+}
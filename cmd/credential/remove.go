@@ -0,0 +1,33 @@
+package credential
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+func init() {
+	Cmd.AddCommand(removeCmd)
+}
+
+var removeCmd = &cobra.Command{
+	Use:         "remove <id>",
+	Short:       "Remove a stored credential",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := credentials.Default()
+		if err != nil {
+			return err
+		}
+
+		if !store.Delete(args[0]) {
+			return fmt.Errorf("credential not found: %s", args[0])
+		}
+
+		return store.Save()
+	},
+}
@@ -0,0 +1,13 @@
+package credential
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "credential",
+	Short: "Manage credentials of remote installations",
+	Long: "Credentials are stored encrypted in the local directory. " +
+		"The store is unlocked with the passphrase in the FICSIT_CREDENTIALS_PASSPHRASE environment variable, " +
+		"or by entering it when prompted. Installations reference a credential with the ?credential=<id> url parameter.",
+}
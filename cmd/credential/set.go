@@ -0,0 +1,82 @@
+package credential
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+)
+
+func init() {
+	setCmd.Flags().String("username", "", "Username to log in with")
+	setCmd.Flags().Bool("password-stdin", false, "Read the password from stdin instead of prompting")
+
+	Cmd.AddCommand(setCmd)
+}
+
+var setCmd = &cobra.Command{
+	Use:         "set <id>",
+	Short:       "Store a credential, prompting for the password",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("username", cmd.Flags().Lookup("username"))
+		_ = viper.BindPFlag("password-stdin", cmd.Flags().Lookup("password-stdin"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := credentials.Default()
+		if err != nil {
+			return err
+		}
+
+		password, err := readPassword(viper.GetBool("password-stdin"))
+		if err != nil {
+			return err
+		}
+
+		store.Set(args[0], credentials.Credential{
+			Username: viper.GetString("username"),
+			Password: password,
+		})
+
+		if err := store.Save(); err != nil {
+			return err
+		}
+
+		println("Use", "?"+credentials.QueryParam+"="+args[0], "in the installation url to reference this credential")
+
+		return nil
+	},
+}
+
+func readPassword(fromStdin bool) (string, error) {
+	if !fromStdin {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", errors.New("no terminal to prompt for the password, use --password-stdin")
+		}
+
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+
+		return string(password), nil
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return strings.TrimRight(password, "\r\n"), nil
+}
@@ -7,13 +7,17 @@ import (
 )
 
 func init() {
+	addCmd.Flags().String("name", "", "Name of the installation (default is derived from the path)")
+	addCmd.Flags().StringSlice("tag", nil, "Tags to add to the installation")
+
 	Cmd.AddCommand(addCmd)
 }
 
 var addCmd = &cobra.Command{
-	Use:   "add <path> [profile]",
-	Short: "Add an installation",
-	Args:  cobra.MinimumNArgs(1),
+	Use:         "add <path> [profile]",
+	Short:       "Add an installation",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
@@ -25,11 +29,22 @@ var addCmd = &cobra.Command{
 			profile = args[1]
 		}
 
-		_, err = global.Installations.AddInstallation(global, args[0], profile)
+		installation, err := global.Installations.AddInstallation(global, args[0], profile)
 		if err != nil {
 			return err
 		}
 
+		if name, _ := cmd.Flags().GetString("name"); name != "" {
+			if err := global.Installations.RenameInstallation(installation, name); err != nil {
+				return err
+			}
+		}
+
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if err := installation.AddTags(tags...); err != nil {
+			return err
+		}
+
 		return global.Save()
 	},
 }
@@ -0,0 +1,43 @@
+package installation
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	addTagSelector(detectCmd)
+
+	Cmd.AddCommand(detectCmd)
+}
+
+var detectCmd = &cobra.Command{
+	Use:   "detect [installation]...",
+	Short: "Show which platform definition matches the installations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installations, err := selectInstallations(cmd, global, args)
+		if err != nil {
+			return err
+		}
+
+		for _, installation := range installations {
+			detection, err := installation.DetectPlatform(global)
+			if err != nil {
+				println(fmt.Sprintf("%s - %s", installation.Name, err))
+				continue
+			}
+
+			println(fmt.Sprintf("%s - %s - %s", installation.Name, detection.Platform.TargetName, detection.Reason()))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,82 @@
+package installation
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	discoverCmd.Flags().Bool("add", false, "Add every discovered installation that is not added yet")
+	discoverCmd.Flags().String("profile", "", "Profile to use for added installations (default is the selected profile)")
+
+	Cmd.AddCommand(discoverCmd)
+}
+
+var discoverCmd = &cobra.Command{
+	Use:         "discover",
+	Short:       "Find game installations on this machine",
+	Annotations: cli.MutatesState,
+	Args:        cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("add", cmd.Flags().Lookup("add"))
+		_ = viper.BindPFlag("discover-profile", cmd.Flags().Lookup("profile"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		discovered, err := global.Installations.DiscoverInstallations(global)
+		if err != nil {
+			return err
+		}
+
+		if len(discovered) == 0 {
+			println("no installations found")
+			return nil
+		}
+
+		for _, installation := range discovered {
+			line := fmt.Sprintf("%s - %s", installation.Path, installation.Source)
+			if installation.Added {
+				line += " (added)"
+			}
+			println(line)
+		}
+
+		if !viper.GetBool("add") {
+			return nil
+		}
+
+		profile := viper.GetString("discover-profile")
+		if profile == "" {
+			profile = global.Profiles.SelectedProfile
+		}
+
+		added := 0
+		for _, installation := range discovered {
+			if installation.Added {
+				continue
+			}
+
+			if _, err := global.Installations.AddInstallation(global, installation.Path, profile); err != nil {
+				return fmt.Errorf("failed to add %s: %w", installation.Path, err)
+			}
+
+			added++
+		}
+
+		if err := global.Save(); err != nil {
+			return err
+		}
+
+		println(fmt.Sprintf("added %d installations", added))
+
+		return nil
+	},
+}
@@ -1,12 +1,17 @@
 package installation
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
 func init() {
+	addTagSelector(lsCmd)
+
 	Cmd.AddCommand(lsCmd)
 }
 
@@ -20,10 +25,37 @@ var lsCmd = &cobra.Command{
 			return err
 		}
 
-		for _, install := range global.Installations.Installations {
-			println(install.Path, "-", install.Profile)
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+
+		installations, err := global.Installations.SelectInstallations(nil, tags)
+		if err != nil {
+			return err
+		}
+
+		for _, install := range installations {
+			line := fmt.Sprintf("%s - %s - %s - %s", install.Name, install.DisplayPath(), install.Profile, gameDescription(global, install))
+			if len(install.Tags) > 0 {
+				line += " [" + strings.Join(install.Tags, ", ") + "]"
+			}
+			println(line)
 		}
 
 		return nil
 	},
 }
+
+func gameDescription(global *cli.GlobalContext, install *cli.Installation) string {
+	game, err := install.GameInfo(global)
+	if err != nil {
+		return "unknown game"
+	}
+
+	description := game.String()
+
+	resolved, err := install.ResolvedGameInfo(global)
+	if err == nil && resolved != nil && !resolved.SameBuild(game) {
+		description += fmt.Sprintf(" (profile resolved against CL%d, apply to update)", resolved.Changelist)
+	}
+
+	return description
+}
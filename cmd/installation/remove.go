@@ -7,24 +7,32 @@ import (
 )
 
 func init() {
+	addTagSelector(removeCmd)
+
 	Cmd.AddCommand(removeCmd)
 }
 
 var removeCmd = &cobra.Command{
-	Use:   "remove <path>",
-	Short: "Remove an installation",
-	Args:  cobra.ExactArgs(1),
+	Use:         "remove [installation]...",
+	Short:       "Remove installations",
+	Annotations: cli.MutatesState,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		err = global.Installations.DeleteInstallation(args[0])
+		installations, err := selectInstallations(cmd, global, args)
 		if err != nil {
 			return err
 		}
 
+		for _, installation := range installations {
+			if err := global.Installations.DeleteInstallation(installation.Path); err != nil {
+				return err
+			}
+		}
+
 		return global.Save()
 	},
 }
@@ -0,0 +1,37 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(renameCmd)
+}
+
+var renameCmd = &cobra.Command{
+	Use:         "rename <installation> <name>",
+	Short:       "Rename an installation",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.GetInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		if err := global.Installations.RenameInstallation(installation, args[1]); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
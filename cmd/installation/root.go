@@ -1,10 +1,28 @@
 package installation
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "installation",
 	Short: "Manage installations",
 }
+
+func addTagSelector(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("tag", nil, "Select the installations with any of these tags")
+}
+
+// selectInstallations returns the installations named by args or matching the --tag selectors
+func selectInstallations(cmd *cobra.Command, global *cli.GlobalContext, args []string) ([]*cli.Installation, error) {
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	if len(args) == 0 && len(tags) == 0 {
+		return nil, errors.New("no installations selected, pass names, paths or --tag")
+	}
+
+	return global.Installations.SelectInstallations(args, tags)
+}
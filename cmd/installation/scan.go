@@ -0,0 +1,117 @@
+package installation
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	scanCmd.Flags().Bool("adopt", false, "Add the unmanaged mods to the installation's profile")
+	scanCmd.Flags().Bool("quarantine", false, "Move the unmanaged mods out of the Mods directory")
+	scanCmd.MarkFlagsMutuallyExclusive("adopt", "quarantine")
+	addTagSelector(scanCmd)
+
+	Cmd.AddCommand(scanCmd)
+}
+
+var scanCmd = &cobra.Command{
+	Use:         "scan [installation]...",
+	Short:       "Find mods that were installed manually",
+	Annotations: cli.MutatesState,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("adopt", cmd.Flags().Lookup("adopt"))
+		_ = viper.BindPFlag("quarantine", cmd.Flags().Lookup("quarantine"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installations, err := selectInstallations(cmd, global, args)
+		if err != nil {
+			return err
+		}
+
+		adopted := false
+		for _, installation := range installations {
+			if len(installations) > 1 {
+				println(installation.Name + ":")
+			}
+
+			changed, err := scanInstallation(global, installation)
+			if err != nil {
+				return err
+			}
+
+			adopted = adopted || changed
+		}
+
+		if !adopted {
+			return nil
+		}
+
+		if err := global.Save(); err != nil {
+			return err
+		}
+
+		println("adopted mods will be replaced by managed copies on the next apply")
+
+		return nil
+	},
+}
+
+// scanInstallation prints the unmanaged mods of an installation and adopts or quarantines them if requested.
+//
+// Returns true if mods were adopted into the installation's profile.
+func scanInstallation(global *cli.GlobalContext, installation *cli.Installation) (bool, error) {
+	mods, err := installation.ScanMods(global)
+	if err != nil {
+		return false, err
+	}
+
+	if len(mods) == 0 {
+		println("no unmanaged mods found")
+		return false, nil
+	}
+
+	for _, mod := range mods {
+		line := fmt.Sprintf("%s@%s - unmanaged", mod.ModReference, mod.Version)
+		if mod.Duplicate != "" {
+			line += ", duplicate of " + mod.Duplicate
+		}
+		if mod.Issue != "" {
+			line += ", " + mod.Issue
+		}
+		println(line)
+	}
+
+	if viper.GetBool("quarantine") {
+		directory, err := installation.QuarantineMods(mods)
+		if err != nil {
+			return false, err
+		}
+
+		println(fmt.Sprintf("moved %d mods to %s", len(mods), directory))
+		return false, nil
+	}
+
+	if !viper.GetBool("adopt") {
+		return false, nil
+	}
+
+	issues, err := installation.AdoptMods(global, mods)
+	if err != nil {
+		return false, err
+	}
+
+	for _, issue := range issues {
+		println(fmt.Sprintf("%s@%s - %s", issue.Reference, issue.Version, issue.Reason))
+	}
+
+	return true, nil
+}
@@ -1,44 +1,39 @@
 package installation
 
 import (
-	"errors"
-
 	"github.com/spf13/cobra"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
 func init() {
+	addTagSelector(setProfileCmd)
+
 	Cmd.AddCommand(setProfileCmd)
 }
 
 var setProfileCmd = &cobra.Command{
-	Use:   "set-profile <path> <profile>",
-	Short: "Change the profile of an installation",
-	Args:  cobra.ExactArgs(2),
+	Use:         "set-profile [installation]... <profile>",
+	Short:       "Change the profile of installations",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		var installation *cli.Installation
-		for _, install := range global.Installations.Installations {
-			if install.Path == args[0] {
-				installation = install
-				break
-			}
-		}
-
-		if installation == nil {
-			return errors.New("installation not found")
-		}
-
-		err = installation.SetProfile(global, args[1])
+		installations, err := selectInstallations(cmd, global, args[:len(args)-1])
 		if err != nil {
 			return err
 		}
 
+		for _, installation := range installations {
+			if err := installation.SetProfile(global, args[len(args)-1]); err != nil {
+				return err
+			}
+		}
+
 		return global.Save()
 	},
 }
@@ -0,0 +1,47 @@
+package installation
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	setSaveBackupCmd.Flags().BoolP("off", "o", false, "Disable automatic save backups")
+	setSaveBackupCmd.Flags().String("save-path", "", "Save directory to back up instead of the platform default")
+	addTagSelector(setSaveBackupCmd)
+
+	Cmd.AddCommand(setSaveBackupCmd)
+}
+
+var setSaveBackupCmd = &cobra.Command{
+	Use:         "set-save-backup [installation]...",
+	Short:       "Enable or disable backing up saves before applying changes",
+	Annotations: cli.MutatesState,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("off", cmd.Flags().Lookup("off"))
+		_ = viper.BindPFlag("save-path", cmd.Flags().Lookup("save-path"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installations, err := selectInstallations(cmd, global, args)
+		if err != nil {
+			return err
+		}
+
+		for _, installation := range installations {
+			installation.AutoBackup = !viper.GetBool("off")
+
+			if cmd.Flags().Changed("save-path") {
+				installation.SavePath = viper.GetString("save-path")
+			}
+		}
+
+		return global.Save()
+	},
+}
@@ -1,8 +1,6 @@
 package installation
 
 import (
-	"errors"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -11,14 +9,15 @@ import (
 
 func init() {
 	setVanillaCmd.Flags().BoolP("off", "o", false, "Disable vanilla")
+	addTagSelector(setVanillaCmd)
 
 	Cmd.AddCommand(setVanillaCmd)
 }
 
 var setVanillaCmd = &cobra.Command{
-	Use:   "set-vanilla <path>",
-	Short: "Set the installation to vanilla mode or not",
-	Args:  cobra.ExactArgs(1),
+	Use:         "set-vanilla [installation]...",
+	Short:       "Set installations to vanilla mode or not",
+	Annotations: cli.MutatesState,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		_ = viper.BindPFlag("off", cmd.Flags().Lookup("off"))
 	},
@@ -28,20 +27,15 @@ var setVanillaCmd = &cobra.Command{
 			return err
 		}
 
-		var installation *cli.Installation
-		for _, install := range global.Installations.Installations {
-			if install.Path == args[0] {
-				installation = install
-				break
-			}
+		installations, err := selectInstallations(cmd, global, args)
+		if err != nil {
+			return err
 		}
 
-		if installation == nil {
-			return errors.New("installation not found")
+		for _, installation := range installations {
+			installation.Vanilla = !viper.GetBool("off")
 		}
 
-		installation.Vanilla = !viper.GetBool("off")
-
 		return global.Save()
 	},
 }
@@ -0,0 +1,45 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	tagCmd.Flags().BoolP("remove", "r", false, "Remove the tags instead of adding them")
+
+	Cmd.AddCommand(tagCmd)
+}
+
+var tagCmd = &cobra.Command{
+	Use:         "tag <installation> <tag>...",
+	Short:       "Add or remove tags of an installation",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(2),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("remove", cmd.Flags().Lookup("remove"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.GetInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		if viper.GetBool("remove") {
+			installation.RemoveTags(args[1:]...)
+		} else if err := installation.AddTags(args[1:]...); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,100 @@
+package installation
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	verifyCmd.Flags().Bool("repair", false, "Re-extract mods that failed verification")
+	addTagSelector(verifyCmd)
+
+	Cmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [installation]...",
+	Short: "Check the installed mod files against their install manifests",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("repair", cmd.Flags().Lookup("repair"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installations, err := selectInstallations(cmd, global, args)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, installation := range installations {
+			if len(installations) > 1 {
+				println(installation.Name + ":")
+			}
+
+			failedMods, err := verifyInstallation(global, installation)
+			if err != nil {
+				return err
+			}
+
+			failed += failedMods
+		}
+
+		if failed > 0 && !viper.GetBool("repair") {
+			return fmt.Errorf("%d mods failed verification, run with --repair to fix them", failed)
+		}
+
+		return nil
+	},
+}
+
+// verifyInstallation prints the verification results of an installation, repairing it if requested,
+// and returns the number of mods that failed verification
+func verifyInstallation(global *cli.GlobalContext, installation *cli.Installation) (int, error) {
+	results, err := installation.Verify(global)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	unverifiable := 0
+	for _, result := range results {
+		println(fmt.Sprintf("%s@%s - %s", result.ModReference, result.Version, result.Status))
+
+		for _, issue := range result.Issues {
+			println(fmt.Sprintf("  %s: %s", issue.Path, issue.Issue))
+		}
+
+		if result.Status.Failed() {
+			failed++
+		} else if result.Status == cli.ModStatusNoManifest {
+			unverifiable++
+		}
+	}
+
+	if !viper.GetBool("repair") {
+		if unverifiable > 0 {
+			println(fmt.Sprintf("%d mods were installed without a manifest, run with --repair to record one", unverifiable))
+		}
+		return failed, nil
+	}
+
+	if failed == 0 && unverifiable == 0 {
+		return 0, nil
+	}
+
+	if err := installation.Repair(global, results); err != nil {
+		return 0, err
+	}
+
+	println(fmt.Sprintf("repaired %d mods, recorded %d manifests", failed, unverifiable))
+
+	return failed, nil
+}
@@ -0,0 +1,31 @@
+package profile
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(cloneCmd)
+}
+
+var cloneCmd = &cobra.Command{
+	Use:         "clone <source> <name>",
+	Short:       "Create a copy of a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		_, err = global.Profiles.CloneProfile(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
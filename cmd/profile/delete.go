@@ -11,9 +11,10 @@ func init() {
 }
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <name>",
-	Short: "Delete a profile",
-	Args:  cobra.ExactArgs(1),
+	Use:         "delete <name>",
+	Short:       "Delete a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	diffCmd.Flags().Bool("resolved", false, "Compare the resolved mod versions instead of the profile constraints")
+	diffCmd.Flags().String("installation", "", "Installation whose game version is used for resolving (defaults to the selected installation)")
+
+	Cmd.AddCommand(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <profile> <profile>",
+	Short: "Show the differences between two profiles",
+	Args:  cobra.ExactArgs(2),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("resolved", cmd.Flags().Lookup("resolved"))
+		_ = viper.BindPFlag("installation", cmd.Flags().Lookup("installation"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		from := global.Profiles.GetProfile(args[0])
+		if from == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		to := global.Profiles.GetProfile(args[1])
+		if to == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[1])
+		}
+
+		var diff []cli.ModDiff
+		if viper.GetBool("resolved") {
+			installPath := viper.GetString("installation")
+			if installPath == "" {
+				installPath = global.Installations.SelectedInstallation
+			}
+
+			installation := global.Installations.GetInstallation(installPath)
+			if installation == nil {
+				return errors.New("installation not found")
+			}
+
+			fromLockFile, err := installation.ResolveProfile(global, from)
+			if err != nil {
+				return fmt.Errorf("failed resolving %s: %w", from.Name, err)
+			}
+
+			toLockFile, err := installation.ResolveProfile(global, to)
+			if err != nil {
+				return fmt.Errorf("failed resolving %s: %w", to.Name, err)
+			}
+
+			diff = cli.DiffLockFiles(fromLockFile, toLockFile)
+		} else {
+			diff = cli.DiffProfiles(from, to)
+		}
+
+		for _, d := range diff {
+			println(d.String())
+		}
+
+		return nil
+	},
+}
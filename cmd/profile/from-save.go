@@ -0,0 +1,52 @@
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/savefile"
+)
+
+func init() {
+	Cmd.AddCommand(fromSaveCmd)
+}
+
+var fromSaveCmd = &cobra.Command{
+	Use:         "from-save <file.sav> [name]",
+	Short:       "Create a profile from the mods used in a save file",
+	Annotations: cli.MutatesState,
+	Args:        cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		header, err := savefile.ReadHeaderFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		if len(args) > 1 {
+			name = args[1]
+		}
+
+		profile, issues, err := global.Profiles.AddProfileFromSave(cmd.Context(), global.Provider, name, header)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			println(fmt.Sprintf("%s@%s: %s", issue.Reference, issue.Version, issue.Reason))
+		}
+
+		println(fmt.Sprintf("created profile %s with %d mods", profile.Name, len(profile.Mods)))
+
+		return global.Save()
+	},
+}
@@ -1,41 +1,81 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
 func init() {
+	addCmd.Flags().String("from-file", "", "Read mods to add from a file, one per line")
+
 	Cmd.AddCommand(addCmd)
 }
 
 var addCmd = &cobra.Command{
-	Use:   "add <profile> <mod-reference> [version]",
-	Short: "Add mod to a profile",
-	Args:  cobra.MinimumNArgs(2),
+	Use:         "add <profile> <mod-reference>[@version] [version] ...",
+	Short:       "Add mods to a profile",
+	Annotations: cli.MutatesState,
+	Long: "Add mods to a profile.\n\n" +
+		"Mods can be passed either by their reference or by their name, in which case the closest match is used.\n" +
+		"The version constraint defaults to >=0.0.0 and can be set either as <mod>@<version> or as a separate argument following the mod.",
+	Args: cobra.MinimumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("from-file", cmd.Flags().Lookup("from-file"))
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		version := ">=0.0.0"
-		if len(args) > 2 {
-			version = args[2]
-		}
-
 		profile := global.Profiles.GetProfile(args[0])
 		if profile == nil {
 			return fmt.Errorf("profile with name %s does not exist", args[0])
 		}
 
-		if err := profile.AddMod(args[1], version); err != nil {
+		mods, err := parseModArgs(args[1:])
+		if err != nil {
 			return err
 		}
 
+		if viper.GetString("from-file") != "" {
+			fileMods, err := readModsFile(viper.GetString("from-file"))
+			if err != nil {
+				return err
+			}
+
+			mods = append(mods, fileMods...)
+		}
+
+		if len(mods) == 0 {
+			return errors.New("no mods provided")
+		}
+
+		for _, mod := range mods {
+			reference, err := cli.ResolveModReference(cmd.Context(), global.Provider, mod.Reference)
+			if err != nil {
+				return err
+			}
+
+			version := mod.Version
+			if version == "" {
+				version = ">=0.0.0"
+			}
+
+			if err := profile.AddMod(reference, version); err != nil {
+				return fmt.Errorf("failed to add %s: %w", reference, err)
+			}
+
+			if reference != mod.Reference {
+				println(fmt.Sprintf("%s resolved to %s", mod.Reference, reference))
+			}
+		}
+
 		return global.Save()
 	},
 }
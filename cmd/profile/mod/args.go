@@ -0,0 +1,95 @@
+package mod
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+type modArg struct {
+	Reference string
+	Version   string
+}
+
+// parseModArgs parses a list of `<mod>[@version]` arguments.
+//
+// Version constraints may also be passed as separate arguments following the mod they apply to.
+func parseModArgs(args []string) ([]modArg, error) {
+	mods := make([]modArg, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// A lone hyphen joins the constraints around it into a hyphen range
+		if arg == "-" && len(mods) > 0 && mods[len(mods)-1].Version != "" {
+			if i+1 >= len(args) || utils.ValidateVersionConstraint(args[i+1]) != nil {
+				return nil, fmt.Errorf("hyphen range of %s has no upper bound", mods[len(mods)-1].Reference)
+			}
+
+			mods[len(mods)-1].Version += " - " + args[i+1]
+			i++
+			continue
+		}
+
+		if utils.ValidateVersionConstraint(arg) == nil {
+			if len(mods) == 0 {
+				return nil, fmt.Errorf("version %s does not follow a mod", arg)
+			}
+
+			// Consecutive constraints form a compound range
+			if mods[len(mods)-1].Version != "" {
+				mods[len(mods)-1].Version += " " + arg
+			} else {
+				mods[len(mods)-1].Version = arg
+			}
+			continue
+		}
+
+		reference, version, _ := strings.Cut(arg, "@")
+		mods = append(mods, modArg{
+			Reference: reference,
+			Version:   version,
+		})
+	}
+
+	return mods, nil
+}
+
+// readModsFile reads a list of mods from a file.
+//
+// Each line contains a single mod in the same format as accepted by parseModArgs.
+// Empty lines and lines starting with # are ignored.
+func readModsFile(path string) ([]modArg, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mods file: %w", err)
+	}
+
+	defer f.Close()
+
+	mods := make([]modArg, 0)
+
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lineMods, err := parseModArgs(strings.Fields(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid mod on line %d: %w", lineNumber, err)
+		}
+
+		mods = append(mods, lineMods...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mods file: %w", err)
+	}
+
+	return mods, nil
+}
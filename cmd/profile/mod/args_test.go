@@ -0,0 +1,23 @@
+package mod
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestParseModArgs(t *testing.T) {
+	mods, err := parseModArgs([]string{"Foo", "1.0.0", "-", "2.0.0", "Bar@^1.2.0", "Baz", ">=1.0.0", "<2.0.0"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []modArg{
+		{Reference: "Foo", Version: "1.0.0 - 2.0.0"},
+		{Reference: "Bar", Version: "^1.2.0"},
+		{Reference: "Baz", Version: ">=1.0.0 <2.0.0"},
+	}, mods)
+
+	_, err = parseModArgs([]string{"Foo", "1.0.0", "-"})
+	testza.AssertNotNil(t, err)
+
+	_, err = parseModArgs([]string{"1.0.0", "Foo"})
+	testza.AssertNotNil(t, err)
+}
@@ -0,0 +1,58 @@
+package mod
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(enableCmd)
+	Cmd.AddCommand(disableCmd)
+}
+
+var enableCmd = &cobra.Command{
+	Use:         "enable <profile> <mod-reference> ...",
+	Short:       "Enable mods in a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setModsEnabled(args[0], args[1:], true)
+	},
+}
+
+var disableCmd = &cobra.Command{
+	Use:         "disable <profile> <mod-reference> ...",
+	Short:       "Disable mods in a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setModsEnabled(args[0], args[1:], false)
+	},
+}
+
+func setModsEnabled(profileName string, references []string, enabled bool) error {
+	global, err := cli.InitCLI(false)
+	if err != nil {
+		return err
+	}
+
+	profile := global.Profiles.GetProfile(profileName)
+	if profile == nil {
+		return fmt.Errorf("profile with name %s does not exist", profileName)
+	}
+
+	for _, reference := range references {
+		if !profile.HasMod(reference) {
+			return fmt.Errorf("profile %s does not contain mod %s", profileName, reference)
+		}
+	}
+
+	for _, reference := range references {
+		profile.SetModEnabled(reference, enabled)
+	}
+
+	return global.Save()
+}
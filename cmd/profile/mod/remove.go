@@ -13,9 +13,10 @@ func init() {
 }
 
 var removeCmd = &cobra.Command{
-	Use:   "remove <profile> <mod-reference>",
-	Short: "Remove a mod from a profile",
-	Args:  cobra.ExactArgs(2),
+	Use:         "remove <profile> <mod-reference>",
+	Short:       "Remove a mod from a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
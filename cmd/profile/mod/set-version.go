@@ -0,0 +1,48 @@
+package mod
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setVersionCmd)
+}
+
+var setVersionCmd = &cobra.Command{
+	Use:         "set-version <profile> <mod-reference>@<version> ...",
+	Short:       "Change the version constraint of mods in a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		mods, err := parseModArgs(args[1:])
+		if err != nil {
+			return err
+		}
+
+		for _, mod := range mods {
+			if mod.Version == "" {
+				return fmt.Errorf("no version provided for %s", mod.Reference)
+			}
+
+			if err := profile.SetModVersion(mod.Reference, mod.Version); err != nil {
+				return fmt.Errorf("failed to set version of %s: %w", mod.Reference, err)
+			}
+		}
+
+		return global.Save()
+	},
+}
@@ -11,9 +11,10 @@ func init() {
 }
 
 var newCmd = &cobra.Command{
-	Use:   "new <name>",
-	Short: "Create a new profile",
-	Args:  cobra.ExactArgs(1),
+	Use:         "new <name>",
+	Short:       "Create a new profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
@@ -11,9 +11,10 @@ func init() {
 }
 
 var renameCmd = &cobra.Command{
-	Use:   "rename <old> <name>",
-	Short: "Rename a profile",
-	Args:  cobra.ExactArgs(2),
+	Use:         "rename <old> <name>",
+	Short:       "Rename a profile",
+	Annotations: cli.MutatesState,
+	Args:        cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// terminalPrompter asks for input on the controlling terminal, hiding the answer when echo is false
+func terminalPrompter(prompt string, echo bool) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if !echo {
+		answer, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		return string(answer), nil
+	}
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return strings.TrimRight(answer, "\r\n"), nil
+}
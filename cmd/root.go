@@ -13,11 +13,18 @@ import (
 	slogmulti "github.com/samber/slog-multi"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/cmd/credential"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/installation"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/mod"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/profile"
+	"github.com/satisfactorymodding/ficsit-cli/cmd/saves"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/smr"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
 
 var RootCmd = &cobra.Command{
@@ -82,10 +89,33 @@ var RootCmd = &cobra.Command{
 			slogmulti.Fanout(handlers...),
 		))
 
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			disk.SetPrompter(terminalPrompter)
+			credentials.SetPassphrasePrompt(func() (string, error) {
+				return terminalPrompter("Credential store passphrase: ", false)
+			})
+		}
+
+		if cmd.Annotations[cli.MutatesStateAnnotation] != "" {
+			unlock, err := cli.LockState()
+			if err != nil {
+				return err
+			}
+			unlockState = unlock
+		}
+
 		return nil
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if unlockState != nil {
+			unlockState()
+		}
+	},
 }
 
+// unlockState releases the state lock held by a command that mutates state
+var unlockState func()
+
 func Execute(version string, commit string) {
 	// Execute tea as default
 	cmd, _, err := RootCmd.Find(os.Args[1:])
@@ -123,6 +153,8 @@ func init() {
 	RootCmd.AddCommand(installation.Cmd)
 	RootCmd.AddCommand(mod.Cmd)
 	RootCmd.AddCommand(smr.Cmd)
+	RootCmd.AddCommand(saves.Cmd)
+	RootCmd.AddCommand(credential.Cmd)
 
 	var baseLocalDir string
 
@@ -150,11 +182,14 @@ func init() {
 	RootCmd.PersistentFlags().Bool("pretty", true, "Whether to render pretty terminal output")
 
 	RootCmd.PersistentFlags().Bool("dry-run", false, "Dry-run. Do not save any changes")
+	RootCmd.PersistentFlags().Duration("lock-timeout", 30*time.Second, "How long to wait for other ficsit processes to release the state lock")
 
 	RootCmd.PersistentFlags().String("cache-dir", filepath.Clean(filepath.Join(baseCacheDir, "ficsit")), "The cache directory")
 	RootCmd.PersistentFlags().String("local-dir", filepath.Clean(filepath.Join(baseLocalDir, "ficsit")), "The local directory")
 	RootCmd.PersistentFlags().String("profiles-file", "profiles.json", "The profiles file")
 	RootCmd.PersistentFlags().String("installations-file", "installations.json", "The installations file")
+	RootCmd.PersistentFlags().String("credentials-file", "credentials.json", "The encrypted credentials file")
+	RootCmd.PersistentFlags().String("platforms-file", "platforms.json", "The file with additional platform definitions")
 
 	RootCmd.PersistentFlags().String("api-base", "https://api.ficsit.app", "URL for API")
 	RootCmd.PersistentFlags().String("graphql-api", "/v2/query", "Path for GraphQL API")
@@ -162,6 +197,16 @@ func init() {
 
 	RootCmd.PersistentFlags().Bool("offline", false, "Whether to only use local data")
 	RootCmd.PersistentFlags().Int("concurrent-downloads", 5, "Maximum number of concurrent downloads")
+	RootCmd.PersistentFlags().Int64("max-mod-size", utils.DefaultArchiveLimits.MaxSize, "Maximum uncompressed size of a mod archive in bytes (0 disables the limit)")
+	RootCmd.PersistentFlags().Int("max-mod-files", utils.DefaultArchiveLimits.MaxFiles, "Maximum number of files in a mod archive (0 disables the limit)")
+
+	RootCmd.PersistentFlags().Bool("accept-host-key", false, "Trust and remember unknown SSH host keys")
+	RootCmd.PersistentFlags().String("known-hosts-file", "", "The SSH known hosts file (default ~/.ssh/known_hosts)")
+
+	RootCmd.PersistentFlags().Bool("backup-saves", false, "Back up the saves of every installation before applying changes")
+	RootCmd.PersistentFlags().Int("save-backup-retention", 10, "Number of save backups to keep per installation (0 keeps all)")
+
+	RootCmd.PersistentFlags().Bool("force-unlock", false, "Remove installation locks left behind by other processes")
 
 	_ = viper.BindPFlag("log", RootCmd.PersistentFlags().Lookup("log"))
 	_ = viper.BindPFlag("log-file", RootCmd.PersistentFlags().Lookup("log-file"))
@@ -169,11 +214,14 @@ func init() {
 	_ = viper.BindPFlag("pretty", RootCmd.PersistentFlags().Lookup("pretty"))
 
 	_ = viper.BindPFlag("dry-run", RootCmd.PersistentFlags().Lookup("dry-run"))
+	_ = viper.BindPFlag("lock-timeout", RootCmd.PersistentFlags().Lookup("lock-timeout"))
 
 	_ = viper.BindPFlag("cache-dir", RootCmd.PersistentFlags().Lookup("cache-dir"))
 	_ = viper.BindPFlag("local-dir", RootCmd.PersistentFlags().Lookup("local-dir"))
 	_ = viper.BindPFlag("profiles-file", RootCmd.PersistentFlags().Lookup("profiles-file"))
 	_ = viper.BindPFlag("installations-file", RootCmd.PersistentFlags().Lookup("installations-file"))
+	_ = viper.BindPFlag("credentials-file", RootCmd.PersistentFlags().Lookup("credentials-file"))
+	_ = viper.BindPFlag("platforms-file", RootCmd.PersistentFlags().Lookup("platforms-file"))
 
 	_ = viper.BindPFlag("api-base", RootCmd.PersistentFlags().Lookup("api-base"))
 	_ = viper.BindPFlag("graphql-api", RootCmd.PersistentFlags().Lookup("graphql-api"))
@@ -181,4 +229,14 @@ func init() {
 
 	_ = viper.BindPFlag("offline", RootCmd.PersistentFlags().Lookup("offline"))
 	_ = viper.BindPFlag("concurrent-downloads", RootCmd.PersistentFlags().Lookup("concurrent-downloads"))
+	_ = viper.BindPFlag("max-mod-size", RootCmd.PersistentFlags().Lookup("max-mod-size"))
+	_ = viper.BindPFlag("max-mod-files", RootCmd.PersistentFlags().Lookup("max-mod-files"))
+
+	_ = viper.BindPFlag("accept-host-key", RootCmd.PersistentFlags().Lookup("accept-host-key"))
+	_ = viper.BindPFlag("known-hosts-file", RootCmd.PersistentFlags().Lookup("known-hosts-file"))
+
+	_ = viper.BindPFlag("backup-saves", RootCmd.PersistentFlags().Lookup("backup-saves"))
+	_ = viper.BindPFlag("save-backup-retention", RootCmd.PersistentFlags().Lookup("save-backup-retention"))
+
+	_ = viper.BindPFlag("force-unlock", RootCmd.PersistentFlags().Lookup("force-unlock"))
 }
@@ -0,0 +1,46 @@
+package saves
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(backupCmd)
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <installation>",
+	Short: "Back up the saves of an installation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.GetInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		backup, err := installation.BackupSaves(global)
+		if err != nil {
+			return err
+		}
+
+		if backup == nil {
+			println("no saves found")
+			return nil
+		}
+
+		println(fmt.Sprintf("created backup %s (%d files, %s)", backup.ID, backup.Files, humanize.Bytes(uint64(backup.Size))))
+
+		return nil
+	},
+}
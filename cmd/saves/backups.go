@@ -0,0 +1,44 @@
+package saves
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(backupsCmd)
+}
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups <installation>",
+	Short: "List save backups of an installation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.GetInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		backups, err := installation.SaveBackups()
+		if err != nil {
+			return err
+		}
+
+		for _, backup := range backups {
+			println(fmt.Sprintf("%s - %s - %d files, %s", backup.ID, backup.Time.Format(time.RFC1123), backup.Files, humanize.Bytes(uint64(backup.Size))))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,32 @@
+package saves
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(restoreCmd)
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <installation> <backup>",
+	Short: "Restore a save backup into an installation",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.GetInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		return installation.RestoreSaves(global, args[1])
+	},
+}
@@ -0,0 +1,10 @@
+package saves
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "saves",
+	Short: "Manage save game backups of installations",
+}
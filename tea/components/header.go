@@ -32,12 +32,22 @@ func (h headerComponent) Update(_ tea.Msg) (tea.Model, tea.Cmd) {
 func (h headerComponent) View() string {
 	out := h.labelStyle.Render("Installation: ")
 	if h.root.GetCurrentInstallation() != nil {
-		out += h.root.GetCurrentInstallation().Path
+		out += h.root.GetCurrentInstallation().DisplayName()
 	} else {
 		out += "None"
 	}
 	out += "\n"
 
+	out += h.labelStyle.Render("Game: ")
+	if game := h.root.GetCurrentGameInfo(); game != nil {
+		out += game.String()
+	} else if h.root.GetCurrentInstallation() != nil {
+		out += "Unknown"
+	} else {
+		out += "N/A"
+	}
+	out += "\n"
+
 	out += h.labelStyle.Render("Profile: ")
 	if h.root.GetCurrentProfile() != nil {
 		out += h.root.GetCurrentProfile().Name
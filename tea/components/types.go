@@ -17,6 +17,8 @@ type RootModel interface {
 
 	GetCurrentInstallation() *cli.Installation
 	SetCurrentInstallation(installation *cli.Installation) error
+	GetCurrentGameInfo() *cli.GameInfo
+	InvalidateGameInfo()
 
 	GetAPIClient() graphql.Client
 	GetProvider() provider.Provider
@@ -19,6 +19,11 @@ type rootModel struct {
 	global             *cli.GlobalContext
 	dependencyResolver resolver.DependencyResolver
 	currentSize        tea.WindowSizeMsg
+
+	// gameInfo caches the game build of gameInfoPath, or nil if it could not be read.
+	// The header would otherwise read it on every render.
+	gameInfo     *cli.GameInfo
+	gameInfoPath string
 }
 
 func newModel(global *cli.GlobalContext) *rootModel {
@@ -59,9 +64,30 @@ func (m *rootModel) GetCurrentInstallation() *cli.Installation {
 func (m *rootModel) SetCurrentInstallation(installation *cli.Installation) error {
 	m.global.Installations.SelectedInstallation = installation.Path
 	m.global.Profiles.SelectedProfile = installation.Profile
+	m.InvalidateGameInfo()
 	return nil
 }
 
+func (m *rootModel) GetCurrentGameInfo() *cli.GameInfo {
+	installation := m.GetCurrentInstallation()
+	if installation == nil {
+		return nil
+	}
+
+	// Failed lookups are cached too, InvalidateGameInfo retries them
+	if m.gameInfoPath != installation.Path {
+		m.gameInfo, _ = installation.GameInfo(m.global)
+		m.gameInfoPath = installation.Path
+	}
+
+	return m.gameInfo
+}
+
+func (m *rootModel) InvalidateGameInfo() {
+	m.gameInfo = nil
+	m.gameInfoPath = ""
+}
+
 func (m *rootModel) GetAPIClient() graphql.Client {
 	return m.global.APIClient
 }
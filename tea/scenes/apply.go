@@ -84,7 +84,7 @@ func NewApply(root components.RootModel, parent tea.Model) tea.Model {
 
 		model.status[installation.Path] = status{
 			modProgresses:   make(map[string]modProgress),
-			installName:     installation.Path,
+			installName:     installation.DisplayPath(),
 			overallProgress: utils.GenericProgress{},
 		}
 
@@ -169,6 +169,7 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		select {
 		case <-m.doneChannel:
 			m.done = true
+			m.root.InvalidateGameInfo()
 			break
 		case update := <-m.updateChannel:
 			s := m.status[update.Installation.Path]
@@ -201,6 +202,7 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status[update.Installation.Path] = s
 			break
 		case err := <-m.errorChannel:
+			m.root.InvalidateGameInfo()
 			wrappedErrMessage := wrap.String(err.Error(), int(float64(m.root.Size().Width)*0.8))
 			errorComponent, _ := components.NewErrorComponent(wrappedErrMessage, 0)
 			m.error = errorComponent
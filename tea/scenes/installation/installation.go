@@ -63,7 +63,7 @@ func NewInstallation(root components.RootModel, parent tea.Model, installationDa
 	model.list = list.New(items, utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
 	model.list.SetShowStatusBar(false)
 	model.list.SetFilteringEnabled(false)
-	model.list.Title = fmt.Sprintf("Installation: %s", installationData.Path)
+	model.list.Title = fmt.Sprintf("Installation: %s", installationData.DisplayName())
 	model.list.Styles = utils.ListStyles
 	model.list.SetSize(model.list.Width(), model.list.Height())
 	model.list.StatusMessageLifetime = time.Second * 3
@@ -124,7 +124,7 @@ func (m installation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.root.SetSize(msg)
 	case updateInstallationNames:
 		m.hadRenamed = true
-		m.list.Title = fmt.Sprintf("Installation: %s", m.installation.Path)
+		m.list.Title = fmt.Sprintf("Installation: %s", m.installation.DisplayName())
 	case components.ErrorComponentTimeoutMsg:
 		m.error = nil
 	}
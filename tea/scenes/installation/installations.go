@@ -116,7 +116,7 @@ func installationsToList(root components.RootModel) []list.Item {
 	for _, installation := range root.GetGlobal().Installations.Installations {
 		temp := installation
 		items[i] = utils.SimpleItem[installations]{
-			ItemTitle: temp.Path,
+			ItemTitle: temp.DisplayName() + " - " + temp.DisplayPath(),
 			Activate: func(msg tea.Msg, currentModel installations) (tea.Model, tea.Cmd) {
 				newModel := NewInstallation(root, currentModel, temp)
 				return newModel, newModel.Init()
@@ -1,6 +1,7 @@
 package installation
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -18,6 +19,8 @@ import (
 	"github.com/muesli/reflow/truncate"
 	"github.com/sahilm/fuzzy"
 
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 	"github.com/satisfactorymodding/ficsit-cli/tea/components"
 	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
 	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
@@ -26,12 +29,13 @@ import (
 var _ tea.Model = (*newInstallation)(nil)
 
 type newInstallation struct {
-	dirList list.Model
-	root    components.RootModel
-	parent  tea.Model
-	error   *components.ErrorComponent
-	title   string
-	input   textinput.Model
+	dirList    list.Model
+	discovered []list.Item
+	root       components.RootModel
+	parent     tea.Model
+	error      *components.ErrorComponent
+	title      string
+	input      textinput.Model
 }
 
 func NewNewInstallation(root components.RootModel, parent tea.Model) tea.Model {
@@ -57,11 +61,12 @@ func NewNewInstallation(root components.RootModel, parent tea.Model) tea.Model {
 	}
 
 	model := newInstallation{
-		root:    root,
-		parent:  parent,
-		input:   textinput.New(),
-		title:   utils.NonListTitleStyle.Render("New Installation"),
-		dirList: l,
+		root:       root,
+		parent:     parent,
+		input:      textinput.New(),
+		title:      utils.NonListTitleStyle.Render("New Installation"),
+		dirList:    l,
+		discovered: make([]list.Item, 0),
 	}
 
 	model.input.Focus()
@@ -71,7 +76,7 @@ func NewNewInstallation(root components.RootModel, parent tea.Model) tea.Model {
 }
 
 func (m newInstallation) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, discoverInstallationsCmd(m.root))
 }
 
 func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -83,22 +88,7 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case keys.KeyEscape:
 			return m.parent, nil
 		case keys.KeyEnter:
-			newInstall, err := m.root.GetGlobal().Installations.AddInstallation(m.root.GetGlobal(), m.input.Value(), m.root.GetGlobal().Profiles.SelectedProfile)
-			if err != nil {
-				errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
-				m.error = errorComponent
-				return m, cmd
-			}
-
-			if m.root.GetCurrentInstallation() == nil {
-				if err := m.root.SetCurrentInstallation(newInstall); err != nil {
-					errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
-					m.error = errorComponent
-					return m, cmd
-				}
-			}
-
-			return m.parent, updateInstallationListCmd
+			return m.addInstallation()
 		case keys.KeyTab:
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
@@ -113,7 +103,9 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			newPath := ""
 			_, err := os.ReadDir(m.input.Value())
-			if err == nil {
+			if filepath.IsAbs(newDir) {
+				newPath = newDir
+			} else if err == nil {
 				newPath = filepath.Join(m.input.Value(), newDir)
 			} else {
 				newPath = filepath.Join(filepath.Dir(m.input.Value()), newDir)
@@ -122,7 +114,7 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.input.SetValue(newPath + string(os.PathSeparator))
 			m.input.CursorEnd()
 
-			listCmd := m.dirList.SetItems(getDirItems(newPath))
+			listCmd := m.dirList.SetItems(m.getItems(newPath))
 			m.dirList.ResetSelected()
 
 			return m, tea.Batch(cmd, listCmd)
@@ -130,7 +122,7 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var cmd tea.Cmd
 			m.input, cmd = m.input.Update(msg)
 
-			cmd = tea.Batch(cmd, m.dirList.SetItems(getDirItems(m.input.Value())))
+			cmd = tea.Batch(cmd, m.dirList.SetItems(m.getItems(m.input.Value())))
 
 			if m.dirList.Index() > len(m.dirList.Items())-1 {
 				m.dirList.ResetSelected()
@@ -148,6 +140,11 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.root.SetSize(msg)
 	case components.ErrorComponentTimeoutMsg:
 		m.error = nil
+	case discoveredInstallations:
+		m.discovered = msg.items
+		if m.input.Value() == "" {
+			return m, m.dirList.SetItems(m.discovered)
+		}
 	default:
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
@@ -157,6 +154,35 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m newInstallation) addInstallation() (tea.Model, tea.Cmd) {
+	newInstall, err := m.root.GetGlobal().Installations.AddInstallation(m.root.GetGlobal(), m.input.Value(), m.root.GetGlobal().Profiles.SelectedProfile)
+	if err != nil {
+		var hostKeyErr *disk.UnknownHostKeyError
+		if errors.As(err, &hostKeyErr) {
+			return NewTrustHostKey(m.root, m, hostKeyErr, m.addInstallation), nil
+		}
+
+		if errors.Is(err, credentials.ErrLocked) {
+			newModel := NewUnlockCredentials(m.root, m, m.addInstallation)
+			return newModel, newModel.Init()
+		}
+
+		errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+		m.error = errorComponent
+		return m, cmd
+	}
+
+	if m.root.GetCurrentInstallation() == nil {
+		if err := m.root.SetCurrentInstallation(newInstall); err != nil {
+			errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+			m.error = errorComponent
+			return m, cmd
+		}
+	}
+
+	return m.parent, updateInstallationListCmd
+}
+
 func (m newInstallation) View() string {
 	style := lipgloss.NewStyle().Padding(1, 2)
 	inputView := style.Render(m.input.View())
@@ -181,6 +207,51 @@ func (m newInstallation) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, mandatory, m.dirList.View())
 }
 
+type discoveredInstallations struct {
+	items []list.Item
+}
+
+// discoverInstallationsCmd looks for installations in the background, as probing the machine can take a while
+func discoverInstallationsCmd(root components.RootModel) tea.Cmd {
+	return func() tea.Msg {
+		return discoveredInstallations{items: getDiscoveredItems(root)}
+	}
+}
+
+// getDiscoveredItems suggests the installations found on this machine that are not added yet
+func getDiscoveredItems(root components.RootModel) []list.Item {
+	items := make([]list.Item, 0)
+
+	discovered, err := root.GetGlobal().Installations.DiscoverInstallations(root.GetGlobal())
+	if err != nil {
+		return items
+	}
+
+	for _, installation := range discovered {
+		if installation.Added {
+			continue
+		}
+
+		items = append(items, utils.SimpleItemExtra[newInstallation, string]{
+			SimpleItem: utils.SimpleItem[newInstallation]{
+				ItemTitle: installation.Path,
+			},
+			Extra: installation.Path,
+		})
+	}
+
+	return items
+}
+
+func (m newInstallation) getItems(inputValue string) []list.Item {
+	if inputValue == "" {
+		globalMatches = nil
+		return m.discovered
+	}
+
+	return getDirItems(inputValue)
+}
+
 // I know this is awful, but beats re-implementing the entire list model
 var globalMatches []fuzzy.Match
 
@@ -0,0 +1,119 @@
+package installation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*trustHostKey)(nil)
+
+type trustHostKey struct {
+	list   list.Model
+	root   components.RootModel
+	parent tea.Model
+	error  *components.ErrorComponent
+}
+
+// NewTrustHostKey asks the user whether to trust an unknown SSH host key.
+// onTrust is called after the key has been written to the known hosts file.
+func NewTrustHostKey(root components.RootModel, parent tea.Model, hostKeyErr *disk.UnknownHostKeyError, onTrust func() (tea.Model, tea.Cmd)) tea.Model {
+	model := trustHostKey{
+		root:   root,
+		parent: parent,
+	}
+
+	items := []list.Item{
+		utils.SimpleItem[trustHostKey]{
+			ItemTitle: "Trust and connect",
+			Activate: func(msg tea.Msg, currentModel trustHostKey) (tea.Model, tea.Cmd) {
+				if err := disk.TrustHostKey(hostKeyErr.Hostname, hostKeyErr.Key); err != nil {
+					errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+					currentModel.error = errorComponent
+					return currentModel, cmd
+				}
+
+				return onTrust()
+			},
+		},
+		utils.SimpleItem[trustHostKey]{
+			ItemTitle: "Cancel",
+			Activate: func(msg tea.Msg, currentModel trustHostKey) (tea.Model, tea.Cmd) {
+				return currentModel.parent, nil
+			},
+		},
+	}
+
+	model.list = list.New(items, utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
+	model.list.SetShowStatusBar(false)
+	model.list.SetFilteringEnabled(false)
+	model.list.Title = fmt.Sprintf("Unknown host %s, %s key fingerprint is %s", hostKeyErr.Hostname, hostKeyErr.Key.Type(), hostKeyErr.Fingerprint())
+	model.list.Styles = utils.ListStyles
+	model.list.SetSize(model.list.Width(), model.list.Height())
+	model.list.KeyMap.Quit.SetHelp("q", "back")
+	model.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		}
+	}
+
+	return model
+}
+
+func (m trustHostKey) Init() tea.Cmd {
+	return nil
+}
+
+func (m trustHostKey) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case "q":
+			return m.parent, nil
+		case keys.KeyEnter:
+			i, ok := m.list.SelectedItem().(utils.SimpleItem[trustHostKey])
+			if ok && i.Activate != nil {
+				newModel, cmd := i.Activate(msg, m)
+				if newModel == nil {
+					newModel = m
+				}
+				return newModel, cmd
+			}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		top, right, bottom, left := lipgloss.NewStyle().Margin(2, 2).GetMargin()
+		m.list.SetSize(msg.Width-left-right, msg.Height-top-bottom)
+		m.root.SetSize(msg)
+	case components.ErrorComponentTimeoutMsg:
+		m.error = nil
+	}
+
+	return m, nil
+}
+
+func (m trustHostKey) View() string {
+	if m.error != nil {
+		err := m.error.View()
+		m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height()-lipgloss.Height(err))
+		return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), err, m.list.View())
+	}
+
+	m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height())
+	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.list.View())
+}
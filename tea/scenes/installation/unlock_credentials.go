@@ -0,0 +1,93 @@
+package installation
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/credentials"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*unlockCredentials)(nil)
+
+type unlockCredentials struct {
+	root     components.RootModel
+	parent   tea.Model
+	error    *components.ErrorComponent
+	onUnlock func() (tea.Model, tea.Cmd)
+	title    string
+	input    textinput.Model
+}
+
+// NewUnlockCredentials asks the user for the passphrase of the credential store.
+// onUnlock is called after the store has been unlocked with it.
+func NewUnlockCredentials(root components.RootModel, parent tea.Model, onUnlock func() (tea.Model, tea.Cmd)) tea.Model {
+	model := unlockCredentials{
+		root:     root,
+		parent:   parent,
+		onUnlock: onUnlock,
+		input:    textinput.New(),
+		title:    utils.NonListTitleStyle.Render("Credential Store Passphrase"),
+	}
+
+	model.input.EchoMode = textinput.EchoPassword
+	model.input.Focus()
+	model.input.Width = root.Size().Width
+
+	return model
+}
+
+func (m unlockCredentials) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m unlockCredentials) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case keys.KeyEscape:
+			return m.parent, nil
+		case keys.KeyEnter:
+			if err := credentials.Unlock(m.input.Value()); err != nil {
+				errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+				m.error = errorComponent
+				return m, cmd
+			}
+
+			return m.onUnlock()
+		default:
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		m.root.SetSize(msg)
+	case components.ErrorComponentTimeoutMsg:
+		m.error = nil
+	default:
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m unlockCredentials) View() string {
+	inputView := lipgloss.NewStyle().Padding(1, 2).Render(m.input.View())
+
+	mandatory := lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.title, inputView)
+
+	if m.error != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, mandatory, m.error.View())
+	}
+
+	return mandatory
+}
@@ -0,0 +1,90 @@
+package profile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*cloneProfile)(nil)
+
+type cloneProfile struct {
+	root       components.RootModel
+	parent     tea.Model
+	error      *components.ErrorComponent
+	title      string
+	sourceName string
+	input      textinput.Model
+}
+
+func NewCloneProfile(root components.RootModel, parent tea.Model, profileData *cli.Profile) tea.Model {
+	model := cloneProfile{
+		root:       root,
+		parent:     parent,
+		input:      textinput.New(),
+		title:      utils.NonListTitleStyle.Render(fmt.Sprintf("Clone Profile: %s", profileData.Name)),
+		sourceName: profileData.Name,
+	}
+
+	model.input.SetValue(profileData.Name + " Copy")
+	model.input.Focus()
+	model.input.Width = root.Size().Width
+
+	return model
+}
+
+func (m cloneProfile) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m cloneProfile) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case keys.KeyEscape:
+			return m.parent, nil
+		case keys.KeyEnter:
+			if _, err := m.root.GetGlobal().Profiles.CloneProfile(m.sourceName, m.input.Value()); err != nil {
+				errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+				m.error = errorComponent
+				return m, cmd
+			}
+
+			return m.parent, updateProfileNamesCmd
+		default:
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		m.root.SetSize(msg)
+	case components.ErrorComponentTimeoutMsg:
+		m.error = nil
+	default:
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m cloneProfile) View() string {
+	inputView := lipgloss.NewStyle().Padding(1, 2).Render(m.input.View())
+
+	if m.error != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.title, m.error.View(), inputView)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.title, inputView)
+}
@@ -0,0 +1,190 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*diffProfile)(nil)
+
+type diffProfile struct {
+	list        list.Model
+	root        components.RootModel
+	parent      tea.Model
+	profile     *cli.Profile
+	error       *components.ErrorComponent
+	resolved    bool
+	showingDiff bool
+}
+
+func NewDiffProfile(root components.RootModel, parent tea.Model, profileData *cli.Profile, resolved bool) tea.Model {
+	model := diffProfile{
+		root:     root,
+		parent:   parent,
+		profile:  profileData,
+		resolved: resolved,
+	}
+
+	model.list = list.New(model.profileItems(), utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
+	model.list.SetShowStatusBar(false)
+	model.list.SetFilteringEnabled(false)
+	model.list.Title = fmt.Sprintf("Compare %s with", profileData.Name)
+	model.list.Styles = utils.ListStyles
+	model.list.SetSize(model.list.Width(), model.list.Height())
+	model.list.StatusMessageLifetime = time.Second * 3
+	model.list.KeyMap.Quit.SetHelp("q", "back")
+	model.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		}
+	}
+
+	return model
+}
+
+func (m diffProfile) profileItems() []list.Item {
+	names := make([]string, 0, len(m.root.GetGlobal().Profiles.Profiles))
+	for name := range m.root.GetGlobal().Profiles.Profiles {
+		if name != m.profile.Name {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	items := make([]list.Item, len(names))
+	for i, name := range names {
+		other := m.root.GetGlobal().Profiles.Profiles[name]
+		items[i] = utils.SimpleItem[diffProfile]{
+			ItemTitle: name,
+			Activate: func(msg tea.Msg, currentModel diffProfile) (tea.Model, tea.Cmd) {
+				diff, err := currentModel.diff(other)
+				if err != nil {
+					errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+					currentModel.error = errorComponent
+					return currentModel, cmd
+				}
+
+				diffItems := make([]list.Item, len(diff))
+				for j, d := range diff {
+					diffItems[j] = utils.SimpleItem[diffProfile]{
+						ItemTitle: d.String(),
+					}
+				}
+
+				if len(diffItems) == 0 {
+					diffItems = append(diffItems, utils.SimpleItem[diffProfile]{
+						ItemTitle: "No differences",
+					})
+				}
+
+				currentModel.showingDiff = true
+				currentModel.list.Title = fmt.Sprintf("Diff: %s -> %s", currentModel.profile.Name, other.Name)
+				currentModel.list.ResetSelected()
+				return currentModel, currentModel.list.SetItems(diffItems)
+			},
+		}
+	}
+
+	return items
+}
+
+func (m diffProfile) diff(other *cli.Profile) ([]cli.ModDiff, error) {
+	if !m.resolved {
+		return cli.DiffProfiles(m.profile, other), nil
+	}
+
+	installation := m.root.GetCurrentInstallation()
+	if installation == nil {
+		return nil, errors.New("no installation selected")
+	}
+
+	fromLockFile, err := installation.ResolveProfile(m.root.GetGlobal(), m.profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving %s: %w", m.profile.Name, err)
+	}
+
+	toLockFile, err := installation.ResolveProfile(m.root.GetGlobal(), other)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving %s: %w", other.Name, err)
+	}
+
+	return cli.DiffLockFiles(fromLockFile, toLockFile), nil
+}
+
+func (m diffProfile) Init() tea.Cmd {
+	return nil
+}
+
+func (m diffProfile) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case "q":
+			if m.showingDiff {
+				m.showingDiff = false
+				m.list.Title = fmt.Sprintf("Compare %s with", m.profile.Name)
+				m.list.ResetSelected()
+				return m, m.list.SetItems(m.profileItems())
+			}
+
+			if m.parent != nil {
+				m.parent.Update(m.root.Size())
+				return m.parent, nil
+			}
+			return m, nil
+		case keys.KeyEnter:
+			i, ok := m.list.SelectedItem().(utils.SimpleItem[diffProfile])
+			if ok {
+				if i.Activate != nil {
+					newModel, cmd := i.Activate(msg, m)
+					if newModel != nil || cmd != nil {
+						if newModel == nil {
+							newModel = m
+						}
+						return newModel, cmd
+					}
+					return m, nil
+				}
+			}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		top, right, bottom, left := lipgloss.NewStyle().Margin(2, 2).GetMargin()
+		m.list.SetSize(msg.Width-left-right, msg.Height-top-bottom)
+		m.root.SetSize(msg)
+	case components.ErrorComponentTimeoutMsg:
+		m.error = nil
+	}
+
+	return m, nil
+}
+
+func (m diffProfile) View() string {
+	if m.error != nil {
+		err := m.error.View()
+		m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height()-lipgloss.Height(err))
+		return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), err, m.list.View())
+	}
+
+	m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height())
+	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.list.View())
+}
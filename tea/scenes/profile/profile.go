@@ -46,6 +46,30 @@ func NewProfile(root components.RootModel, parent tea.Model, profileData *cli.Pr
 				return currentModel.parent, nil
 			},
 		},
+		utils.SimpleItem[profile]{
+			ItemTitle: "Clone",
+			Activate: func(msg tea.Msg, currentModel profile) (tea.Model, tea.Cmd) {
+				newModel := NewCloneProfile(root, currentModel, profileData)
+				return newModel, newModel.Init()
+			},
+		},
+		utils.SimpleItem[profile]{
+			ItemTitle: "Diff",
+			Activate: func(msg tea.Msg, currentModel profile) (tea.Model, tea.Cmd) {
+				newModel := NewDiffProfile(root, currentModel, profileData, false)
+				return newModel, newModel.Init()
+			},
+		},
+	}
+
+	if root.GetCurrentInstallation() != nil {
+		items = append(items, utils.SimpleItem[profile]{
+			ItemTitle: "Diff (resolved)",
+			Activate: func(msg tea.Msg, currentModel profile) (tea.Model, tea.Cmd) {
+				newModel := NewDiffProfile(root, currentModel, profileData, true)
+				return newModel, newModel.Init()
+			},
+		})
 	}
 
 	if profileData.Name != cli.DefaultProfileName {
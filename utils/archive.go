@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	ErrArchivePathTraversal  = errors.New("path leaves the mod directory")
+	ErrArchiveSymlink        = errors.New("symlinks are not allowed")
+	ErrArchiveTooLarge       = errors.New("uncompressed size exceeds the limit")
+	ErrArchiveTooManyFiles   = errors.New("file count exceeds the limit")
+	ErrArchiveMissingUplugin = errors.New("archive does not contain the mod's .uplugin")
+)
+
+// ArchiveError describes why a mod archive was refused
+type ArchiveError struct {
+	Err   error
+	Mod   string
+	Entry string
+}
+
+func (e ArchiveError) Error() string {
+	if e.Entry == "" {
+		return fmt.Sprintf("unsafe archive for %s: %s", e.Mod, e.Err)
+	}
+
+	return fmt.Sprintf("unsafe archive for %s: %q: %s", e.Mod, e.Entry, e.Err)
+}
+
+func (e ArchiveError) Unwrap() error {
+	return e.Err
+}
+
+type ArchiveLimits struct {
+	// MaxSize is the maximum total uncompressed size in bytes, 0 disables the limit
+	MaxSize int64
+	// MaxFiles is the maximum number of files, 0 disables the limit
+	MaxFiles int
+}
+
+// DefaultArchiveLimits comfortably fit the largest published mods
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxSize:  8 << 30,
+	MaxFiles: 50000,
+}
+
+// archiveEntryPath returns the slash separated path of the entry within the mod directory
+func archiveEntryPath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+
+	// Drive letters and UNC paths are only absolute on windows, so they are checked regardless of the current OS
+	if path.IsAbs(name) || strings.Contains(name, ":") {
+		return "", ErrArchivePathTraversal
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrArchivePathTraversal
+	}
+
+	return cleaned, nil
+}
+
+// ValidateModArchive checks every entry of the archive before anything is extracted.
+//
+// The archive must contain <modReference>.uplugin at its root, may not contain symlinks
+// or entries outside of the mod directory, and has to stay within the provided limits.
+// Returns an ArchiveError describing the first violation.
+func ValidateModArchive(reader *zip.Reader, modReference string, limits ArchiveLimits) error {
+	totalSize := uint64(0)
+	files := 0
+	hasUplugin := false
+
+	for _, file := range reader.File {
+		entryPath, err := archiveEntryPath(file.Name)
+		if err != nil {
+			return ArchiveError{Mod: modReference, Entry: file.Name, Err: err}
+		}
+
+		if file.Mode()&os.ModeSymlink != 0 {
+			return ArchiveError{Mod: modReference, Entry: file.Name, Err: ErrArchiveSymlink}
+		}
+
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		files++
+		if limits.MaxFiles > 0 && files > limits.MaxFiles {
+			return ArchiveError{Mod: modReference, Err: fmt.Errorf("%w of %d", ErrArchiveTooManyFiles, limits.MaxFiles)}
+		}
+
+		// archive/zip fails reading entries that decompress past their declared size, so the declared sizes can be trusted
+		totalSize += file.UncompressedSize64
+		if limits.MaxSize > 0 && totalSize > uint64(limits.MaxSize) {
+			return ArchiveError{Mod: modReference, Err: fmt.Errorf("%w of %d bytes", ErrArchiveTooLarge, limits.MaxSize)}
+		}
+
+		if strings.EqualFold(entryPath, modReference+".uplugin") {
+			hasUplugin = true
+		}
+	}
+
+	if !hasUplugin {
+		return ArchiveError{Mod: modReference, Err: ErrArchiveMissingUplugin}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+func TestValidateModArchive(t *testing.T) {
+	uplugin := ArchiveEntry{Name: "Example.uplugin", Content: "{}"}
+
+	cases := map[string]struct {
+		err     error
+		limits  ArchiveLimits
+		entries []ArchiveEntry
+	}{
+		"valid": {
+			entries: []ArchiveEntry{uplugin, {Name: "Content/Paks/Example.pak", Content: "pak"}, {Name: "Content/./Other/../x.pak"}},
+		},
+		"parent traversal": {
+			entries: []ArchiveEntry{uplugin, {Name: "../../Engine/Binaries/evil.dll"}},
+			err:     ErrArchivePathTraversal,
+		},
+		"nested traversal": {
+			entries: []ArchiveEntry{uplugin, {Name: "Content/../../evil.dll"}},
+			err:     ErrArchivePathTraversal,
+		},
+		"backslash traversal": {
+			entries: []ArchiveEntry{uplugin, {Name: "..\\evil.dll"}},
+			err:     ErrArchivePathTraversal,
+		},
+		"absolute": {
+			entries: []ArchiveEntry{uplugin, {Name: "/etc/passwd"}},
+			err:     ErrArchivePathTraversal,
+		},
+		"drive letter": {
+			entries: []ArchiveEntry{uplugin, {Name: "C:/Windows/evil.dll"}},
+			err:     ErrArchivePathTraversal,
+		},
+		"symlink": {
+			entries: []ArchiveEntry{uplugin, {Name: "link", Content: "/etc", Mode: os.ModeSymlink | 0o777}},
+			err:     ErrArchiveSymlink,
+		},
+		"too many files": {
+			entries: []ArchiveEntry{uplugin, {Name: "a"}, {Name: "b"}},
+			limits:  ArchiveLimits{MaxFiles: 2},
+			err:     ErrArchiveTooManyFiles,
+		},
+		"too large": {
+			entries: []ArchiveEntry{uplugin, {Name: "a", Content: "12345"}},
+			limits:  ArchiveLimits{MaxSize: 4},
+			err:     ErrArchiveTooLarge,
+		},
+		"missing uplugin": {
+			entries: []ArchiveEntry{{Name: "Other.uplugin", Content: "{}"}, {Name: "Content/Example.uplugin"}},
+			err:     ErrArchiveMissingUplugin,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			archive, err := BuildArchive(c.entries...)
+			testza.AssertNoError(t, err)
+
+			reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+			testza.AssertNoError(t, err)
+
+			err = ValidateModArchive(reader, "Example", c.limits)
+			if c.err == nil {
+				testza.AssertNoError(t, err)
+				return
+			}
+
+			testza.AssertTrue(t, errors.Is(err, c.err), err)
+
+			var archiveErr ArchiveError
+			testza.AssertTrue(t, errors.As(err, &archiveErr))
+			testza.AssertEqual(t, "Example", archiveErr.Mod)
+		})
+	}
+}
+
+func TestExtractModRejectsUnsafeArchive(t *testing.T) {
+	d := disk.NewMemory()
+
+	testza.AssertNoError(t, d.MkDir("/Mods/Example"))
+	testza.AssertNoError(t, d.Write("/Mods/Example/Example.uplugin", []byte("installed")))
+
+	archive, err := BuildArchive(ArchiveEntry{Name: "Example.uplugin"}, ArchiveEntry{Name: "../Other/evil.dll"})
+	testza.AssertNoError(t, err)
+
+	err = ExtractMod(bytes.NewReader(archive), int64(len(archive)), "/Mods/Example", "hash", DefaultArchiveLimits, nil, d)
+	testza.AssertTrue(t, errors.Is(err, ErrArchivePathTraversal))
+
+	exists, err := d.Exists("/Mods/Other")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	data, err := d.Read("/Mods/Example/Example.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "installed", string(data), "the installed mod is left untouched")
+}
@@ -4,10 +4,15 @@ import (
 	"archive/zip"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
@@ -22,7 +27,12 @@ func SHA256Data(f io.Reader) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates chan<- GenericProgress, d disk.Disk) error {
+// ExtractMod extracts the mod archive into location, which has to be named after the mod reference.
+//
+// The archive is validated against the limits before the existing mod is touched.
+// Updates of mods with an install manifest only write the files that changed and delete the removed ones,
+// everything else is extracted from scratch.
+func ExtractMod(f io.ReaderAt, size int64, location string, hash string, limits ArchiveLimits, updates chan<- GenericProgress, d disk.Disk) error {
 	hashFile := filepath.Join(location, ".smm")
 
 	exists, err := d.Exists(hashFile)
@@ -30,17 +40,47 @@ func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates
 		return err
 	}
 
+	installedHash := ""
 	if exists {
 		hashBytes, err := d.Read(hashFile)
 		if err != nil {
 			return fmt.Errorf("failed to read .smm mod hash file: %w", err)
 		}
 
-		if hash == string(hashBytes) {
+		installedHash = string(hashBytes)
+		if hash == installedHash {
 			return nil
 		}
 	}
 
+	reader, err := zip.NewReader(f, size)
+	if err != nil {
+		return fmt.Errorf("failed to read file as zip: %w", err)
+	}
+
+	if err := ValidateModArchive(reader, filepath.Base(location), limits); err != nil {
+		return err
+	}
+
+	if installedHash != "" {
+		previous, err := ReadModManifest(location, d)
+		if err != nil && !errors.Is(err, ErrNoManifest) {
+			return err
+		}
+
+		// The manifest only describes the files on disk if it was completed by the hash file
+		if previous != nil && previous.Hash == installedHash {
+			slog.Debug("updating mod incrementally", slog.String("path", location))
+
+			// Until the update completes, the mod has to be treated as broken
+			if err := d.Remove(hashFile); err != nil {
+				return fmt.Errorf("failed to remove .smm mod hash file: %w", err)
+			}
+
+			return extractFiles(reader, location, hash, previous, updates, d)
+		}
+	}
+
 	exists, err = d.Exists(location)
 	if err != nil {
 		return err
@@ -56,11 +96,30 @@ func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates
 		}
 	}
 
-	reader, err := zip.NewReader(f, size)
-	if err != nil {
-		return fmt.Errorf("failed to read file as zip: %w", err)
+	if extractor, ok := d.(disk.ArchiveExtractor); ok {
+		err := extractRemote(f, size, location, extractor, d, updates)
+		if err == nil {
+			manifest, err := BuildModManifest(f, size, hash)
+			if err != nil {
+				return err
+			}
+
+			return writeModHash(location, manifest, size, updates, d)
+		}
+
+		if !errors.Is(err, disk.ErrRemoteExtractUnsupported) {
+			return err
+		}
 	}
 
+	return extractFiles(reader, location, hash, nil, updates, d)
+}
+
+// extractFiles writes the files of the archive to location.
+//
+// If a previous manifest is provided, files with the same size and CRC32 are kept
+// and files missing from the archive are deleted.
+func extractFiles(reader *zip.Reader, location string, hash string, previous *ModManifest, updates chan<- GenericProgress, d disk.Disk) error {
 	totalSize := int64(0)
 
 	for _, file := range reader.File {
@@ -69,9 +128,27 @@ func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates
 
 	totalExtracted := int64(0)
 
+	manifest := &ModManifest{
+		Hash:  hash,
+		Files: make(map[string]ManifestFile),
+	}
+
 	for _, file := range reader.File {
 		if !file.FileInfo().IsDir() {
-			outFileLocation := filepath.Join(location, file.Name)
+			entryPath, err := archiveEntryPath(file.Name)
+			if err != nil {
+				return ArchiveError{Mod: filepath.Base(location), Entry: file.Name, Err: err}
+			}
+
+			if previous != nil {
+				if existing, ok := previous.Files[entryPath]; ok && existing.Size == int64(file.UncompressedSize64) && existing.CRC32 == file.CRC32 && existing.CRC32 != 0 {
+					manifest.Files[entryPath] = existing
+					totalExtracted += int64(file.UncompressedSize64)
+					continue
+				}
+			}
+
+			outFileLocation := filepath.Join(location, filepath.FromSlash(entryPath))
 
 			if err := d.MkDir(filepath.Dir(outFileLocation)); err != nil {
 				return fmt.Errorf("failed to create mod directory: %s: %w", location, err)
@@ -95,18 +172,93 @@ func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates
 				}()
 			}
 
-			if err := writeZipFile(outFileLocation, file, d, fileUpdates); err != nil {
+			fileHash, err := writeZipFile(outFileLocation, file, d, fileUpdates)
+			if err != nil {
 				channelUsers.Wait()
 				return err
 			}
 
 			channelUsers.Wait()
 
+			manifest.Files[entryPath] = ManifestFile{
+				Hash:  fileHash,
+				Size:  int64(file.UncompressedSize64),
+				CRC32: file.CRC32,
+			}
+
 			totalExtracted += int64(file.UncompressedSize64)
 		}
 	}
 
-	if err := d.Write(hashFile, []byte(hash)); err != nil {
+	if previous != nil {
+		if err := removeStaleFiles(location, previous, manifest, d); err != nil {
+			return err
+		}
+	}
+
+	return writeModHash(location, manifest, totalSize, updates, d)
+}
+
+// removeStaleFiles deletes the files of the previous manifest that are not part of the current one,
+// along with any directories left empty by them
+func removeStaleFiles(location string, previous *ModManifest, current *ModManifest, d disk.Disk) error {
+	dirs := make(map[string]bool)
+
+	for name := range previous.Files {
+		if _, ok := current.Files[name]; ok {
+			continue
+		}
+
+		slog.Debug("removing stale mod file", slog.String("path", location), slog.String("file", name))
+
+		if err := d.Remove(filepath.Join(location, filepath.FromSlash(name))); err != nil {
+			return fmt.Errorf("failed to remove stale file: %s: %w", name, err)
+		}
+
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	sortedDirs := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+
+	// Deepest first, so parents are only checked after their children are gone
+	sort.Slice(sortedDirs, func(i, j int) bool {
+		return strings.Count(sortedDirs[i], "/") > strings.Count(sortedDirs[j], "/")
+	})
+
+	for _, dir := range sortedDirs {
+		dirPath := filepath.Join(location, filepath.FromSlash(dir))
+
+		entries, err := d.ReadDir(dirPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("failed to read directory: %s: %w", dir, err)
+		}
+
+		if len(entries) == 0 {
+			if err := d.Remove(dirPath); err != nil {
+				return fmt.Errorf("failed to remove empty directory: %s: %w", dir, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeModHash marks the mod as fully extracted, the manifest is written first so it always matches the hash file
+func writeModHash(location string, manifest *ModManifest, totalSize int64, updates chan<- GenericProgress, d disk.Disk) error {
+	if err := WriteModManifest(location, manifest, d); err != nil {
+		return err
+	}
+
+	if err := d.Write(filepath.Join(location, ".smm"), []byte(manifest.Hash)); err != nil {
 		return fmt.Errorf("failed to write .smm mod hash file: %w", err)
 	}
 
@@ -117,21 +269,45 @@ func ExtractMod(f io.ReaderAt, size int64, location string, hash string, updates
 	return nil
 }
 
-func writeZipFile(outFileLocation string, file *zip.File, d disk.Disk, updates chan<- GenericProgress) error {
+// extractRemote lets the disk extract the archive on its side, reporting the upload as progress
+func extractRemote(f io.ReaderAt, size int64, location string, extractor disk.ArchiveExtractor, d disk.Disk, updates chan<- GenericProgress) error {
+	if err := d.MkDir(location); err != nil {
+		return fmt.Errorf("failed to create mod directory: %s: %w", location, err)
+	}
+
+	progress := &Progresser{
+		Total:   size,
+		Updates: updates,
+	}
+
+	if err := extractor.ExtractArchive(io.TeeReader(io.NewSectionReader(f, 0, size), progress), location); err != nil {
+		return fmt.Errorf("failed to extract mod remotely: %w", err)
+	}
+
+	return nil
+}
+
+// writeZipFile extracts the file and returns the hash of its contents
+func writeZipFile(outFileLocation string, file *zip.File, d disk.Disk, updates chan<- GenericProgress) (string, error) {
 	if updates != nil {
 		defer close(updates)
 	}
 
-	outFile, err := d.Open(outFileLocation, os.O_CREATE|os.O_RDWR)
+	outFile, err := d.Open(outFileLocation, os.O_CREATE|os.O_RDWR|os.O_TRUNC)
 	if err != nil {
-		return fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
+		return "", fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
 	}
 
-	defer outFile.Close()
+	closed := false
+	defer func() {
+		if !closed {
+			_ = outFile.Close()
+		}
+	}()
 
 	inFile, err := file.Open()
 	if err != nil {
-		return fmt.Errorf("failed to process mod zip: %w", err)
+		return "", fmt.Errorf("failed to process mod zip: %w", err)
 	}
 	defer inFile.Close()
 
@@ -140,9 +316,17 @@ func writeZipFile(outFileLocation string, file *zip.File, d disk.Disk, updates c
 		Updates: updates,
 	}
 
-	if _, err := io.Copy(io.MultiWriter(outFile, progressInWriter), inFile); err != nil {
-		return fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
+	h := sha256.New()
+
+	if _, err := io.Copy(io.MultiWriter(outFile, progressInWriter, h), inFile); err != nil {
+		return "", fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
+	}
+
+	// Remote disks only report upload failures when closing
+	closed = true
+	if err := outFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
 	}
 
-	return nil
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// recordingDisk records every path opened for writing
+type recordingDisk struct {
+	disk.Disk
+	opened []string
+}
+
+func (r *recordingDisk) Open(path string, flag int) (io.WriteCloser, error) {
+	r.opened = append(r.opened, path)
+	return r.Disk.Open(path, flag) //nolint:wrapcheck
+}
+
+func extractArchive(t *testing.T, d disk.Disk, hash string, entries ...ArchiveEntry) {
+	t.Helper()
+
+	archive, err := BuildArchive(entries...)
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, ExtractMod(bytes.NewReader(archive), int64(len(archive)), "/Mods/Example", hash, DefaultArchiveLimits, nil, d))
+}
+
+func TestExtractModIncremental(t *testing.T) {
+	d := &recordingDisk{Disk: disk.NewMemory()}
+	testza.AssertNoError(t, d.MkDir("/Mods"))
+
+	extractArchive(t, d, "v1",
+		ArchiveEntry{Name: "Example.uplugin", Content: "{}"},
+		ArchiveEntry{Name: "Content/A.pak", Content: "a"},
+		ArchiveEntry{Name: "Content/B.pak", Content: "b"},
+		ArchiveEntry{Name: "Old/Nested/C.pak", Content: "c"},
+	)
+	testza.AssertLen(t, d.opened, 4)
+
+	d.opened = nil
+	extractArchive(t, d, "v2",
+		ArchiveEntry{Name: "Example.uplugin", Content: "{}"},
+		ArchiveEntry{Name: "Content/A.pak", Content: "a2"},
+		ArchiveEntry{Name: "Content/B.pak", Content: "b"},
+		ArchiveEntry{Name: "New.pak", Content: "n"},
+	)
+
+	sort.Strings(d.opened)
+	testza.AssertEqual(t, []string{"/Mods/Example/Content/A.pak", "/Mods/Example/New.pak"}, d.opened)
+
+	data, err := d.Read("/Mods/Example/Content/A.pak")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "a2", string(data))
+
+	exists, err := d.Exists("/Mods/Example/Old")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists, "directories emptied by the update are removed")
+
+	data, err = d.Read("/Mods/Example/.smm")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "v2", string(data))
+
+	issues, err := VerifyMod("/Mods/Example", d)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, issues, 0)
+
+	// A manifest that was not completed by the hash file can not be trusted
+	testza.AssertNoError(t, d.Write("/Mods/Example/.smm", []byte("interrupted")))
+
+	d.opened = nil
+	extractArchive(t, d, "v3",
+		ArchiveEntry{Name: "Example.uplugin", Content: "{}"},
+		ArchiveEntry{Name: "Content/A.pak", Content: "a2"},
+	)
+	testza.AssertLen(t, d.opened, 2)
+
+	// Without a manifest the mod is extracted from scratch
+	testza.AssertNoError(t, d.Remove("/Mods/Example/"+ModManifestFile))
+
+	d.opened = nil
+	extractArchive(t, d, "v4",
+		ArchiveEntry{Name: "Example.uplugin", Content: "{}"},
+		ArchiveEntry{Name: "Content/A.pak", Content: "a2"},
+	)
+	testza.AssertLen(t, d.opened, 2)
+
+	exists, err = d.Exists("/Mods/Example/New.pak")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+}
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// ModManifestFile is the name of the manifest written next to the .smm hash file of every extracted mod
+const ModManifestFile = ".smm-manifest.json"
+
+// ErrNoManifest is returned by VerifyMod for mods extracted before manifests were written
+var ErrNoManifest = errors.New("mod has no install manifest")
+
+// ModManifest records every file extracted from a mod archive
+type ModManifest struct {
+	// Files is keyed by the slash separated path relative to the mod directory
+	Files map[string]ManifestFile `json:"files"`
+	// Hash is the hash of the archive the files were extracted from
+	Hash string `json:"hash"`
+}
+
+type ManifestFile struct {
+	Hash  string `json:"hash"`
+	Size  int64  `json:"size"`
+	CRC32 uint32 `json:"crc32"`
+}
+
+type FileIssue string
+
+const (
+	FileIssueMissing      FileIssue = "missing"
+	FileIssueSizeMismatch FileIssue = "size mismatch"
+	FileIssueHashMismatch FileIssue = "hash mismatch"
+)
+
+type ModFileIssue struct {
+	Path  string
+	Issue FileIssue
+}
+
+// BuildModManifest hashes every file within the archive
+func BuildModManifest(f io.ReaderAt, size int64, hash string) (*ModManifest, error) {
+	reader, err := zip.NewReader(f, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file as zip: %w", err)
+	}
+
+	manifest := &ModManifest{
+		Hash:  hash,
+		Files: make(map[string]ManifestFile),
+	}
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		entryPath, err := archiveEntryPath(file.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		inFile, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to process mod zip: %w", err)
+		}
+
+		fileHash, err := SHA256Data(inFile)
+		_ = inFile.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Files[entryPath] = ManifestFile{
+			Hash:  fileHash,
+			Size:  int64(file.UncompressedSize64),
+			CRC32: file.CRC32,
+		}
+	}
+
+	return manifest, nil
+}
+
+// ReadModManifest returns the manifest of the mod extracted at location
+//
+// Returns ErrNoManifest if the mod has none
+func ReadModManifest(location string, d disk.Disk) (*ModManifest, error) {
+	manifestFile := filepath.Join(location, ModManifestFile)
+
+	exists, err := d.Exists(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrNoManifest
+	}
+
+	data, err := d.Read(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mod manifest: %w", err)
+	}
+
+	var manifest ModManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse mod manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// WriteModManifest writes the manifest into the mod directory at location
+func WriteModManifest(location string, manifest *ModManifest, d disk.Disk) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to serialize mod manifest: %w", err)
+	}
+
+	if err := d.Write(filepath.Join(location, ModManifestFile), data); err != nil {
+		return fmt.Errorf("failed to write mod manifest: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyMod compares the files of the mod extracted at location against its manifest.
+//
+// Files that are not part of the manifest, such as configs created by the mod, are ignored.
+// Returns ErrNoManifest if the mod has none.
+func VerifyMod(location string, d disk.Disk) ([]ModFileIssue, error) {
+	manifest, err := ReadModManifest(location, d)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	issues := make([]ModFileIssue, 0)
+	for _, name := range names {
+		expected := manifest.Files[name]
+		filePath := filepath.Join(location, filepath.FromSlash(name))
+
+		entry, err := d.Stat(filePath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				issues = append(issues, ModFileIssue{Path: name, Issue: FileIssueMissing})
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+
+		if entry.IsDir() {
+			issues = append(issues, ModFileIssue{Path: name, Issue: FileIssueMissing})
+			continue
+		}
+
+		if entry.Size() != expected.Size {
+			issues = append(issues, ModFileIssue{Path: name, Issue: FileIssueSizeMismatch})
+			continue
+		}
+
+		data, err := d.Read(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		fileHash, err := SHA256Data(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		if fileHash != expected.Hash {
+			issues = append(issues, ModFileIssue{Path: name, Issue: FileIssueHashMismatch})
+		}
+	}
+
+	return issues, nil
+}
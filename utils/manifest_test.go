@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+func TestVerifyMod(t *testing.T) {
+	d := disk.NewMemory()
+	archive, err := BuildArchive(
+		ArchiveEntry{Name: "Example.uplugin", Content: "{}"},
+		ArchiveEntry{Name: "Content/Paks/LinuxServer/Pak.pak", Content: "pak"},
+		ArchiveEntry{Name: "Binaries/LinuxServer/libMod.so", Content: "lib"},
+	)
+	testza.AssertNoError(t, err)
+
+	testza.AssertNoError(t, d.MkDir("/Mods/Example"))
+	testza.AssertNoError(t, ExtractMod(bytes.NewReader(archive), int64(len(archive)), "/Mods/Example", "hash", DefaultArchiveLimits, nil, d))
+
+	manifest, err := ReadModManifest("/Mods/Example", d)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "hash", manifest.Hash)
+	testza.AssertLen(t, manifest.Files, 3)
+	testza.AssertEqual(t, int64(3), manifest.Files["Content/Paks/LinuxServer/Pak.pak"].Size)
+
+	built, err := BuildModManifest(bytes.NewReader(archive), int64(len(archive)), "hash")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, manifest, built)
+
+	issues, err := VerifyMod("/Mods/Example", d)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, issues, 0)
+
+	testza.AssertNoError(t, d.Write("/Mods/Example/Config.ini", []byte("created by the mod")))
+	testza.AssertNoError(t, d.Remove("/Mods/Example/Example.uplugin"))
+	testza.AssertNoError(t, d.Write("/Mods/Example/Content/Paks/LinuxServer/Pak.pak", []byte("pa")))
+	testza.AssertNoError(t, d.Write("/Mods/Example/Binaries/LinuxServer/libMod.so", []byte("bad")))
+
+	issues, err = VerifyMod("/Mods/Example", d)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []ModFileIssue{
+		{Path: "Binaries/LinuxServer/libMod.so", Issue: FileIssueHashMismatch},
+		{Path: "Content/Paks/LinuxServer/Pak.pak", Issue: FileIssueSizeMismatch},
+		{Path: "Example.uplugin", Issue: FileIssueMissing},
+	}, issues)
+
+	testza.AssertNoError(t, d.MkDir("/Mods/Old"))
+	_, err = VerifyMod("/Mods/Old", d)
+	testza.AssertTrue(t, errors.Is(err, ErrNoManifest))
+}
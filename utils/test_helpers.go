@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// ArchiveEntry is a file of an archive built by BuildArchive
+type ArchiveEntry struct {
+	Name    string
+	Content string
+	Mode    os.FileMode
+}
+
+// BuildArchive creates a zip archive with the entries, in order
+func BuildArchive(entries ...ArchiveEntry) ([]byte, error) {
+	var buffer bytes.Buffer
+	w := zip.NewWriter(&buffer)
+
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.Name}
+		if entry.Mode != 0 {
+			header.SetMode(entry.Mode)
+		}
+
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive entry %s: %w", entry.Name, err)
+		}
+
+		if _, err := f.Write([]byte(entry.Content)); err != nil {
+			return nil, fmt.Errorf("failed to write archive entry %s: %w", entry.Name, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
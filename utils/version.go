@@ -1,5 +1,136 @@
 package utils
 
-import "regexp"
+import (
+	"fmt"
+	"strings"
 
-var SemVerRegex = regexp.MustCompile(`^(<=|<|>|>=|\^)?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+	"github.com/mircearoata/pubgrub-go/pubgrub/semver"
+)
+
+// VersionConstraintLatest is an alias for always using the newest available version
+const VersionConstraintLatest = "latest"
+
+type VersionConstraintError struct {
+	Constraint string
+	Token      string
+	Reason     string
+	Column     int
+}
+
+func (e VersionConstraintError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("invalid version constraint %q: %s", e.Constraint, e.Reason)
+	}
+
+	return fmt.Sprintf("invalid version constraint %q at column %d: %s %q", e.Constraint, e.Column, e.Reason, e.Token)
+}
+
+// NormalizeVersionConstraint returns the constraint in the form understood by the resolver.
+func NormalizeVersionConstraint(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	if strings.EqualFold(constraint, VersionConstraintLatest) {
+		return "*"
+	}
+	return constraint
+}
+
+// ParseVersionConstraint parses a version constraint using the same parser as the resolver.
+//
+// Supported are exact versions (1.2.3), comparators (>=1.2.3), caret (^1.2.3) and tilde (~1.2.3) ranges,
+// wildcards (1.2.x, *), hyphen ranges (1.2.3 - 2.0.0), space separated compound ranges (>=1.2.0 <2.0.0),
+// alternatives separated by || and the "latest" alias.
+//
+// Returns a VersionConstraintError pointing at the offending token if the constraint is invalid.
+func ParseVersionConstraint(constraint string) (semver.Constraint, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return semver.Constraint{}, VersionConstraintError{
+			Constraint: constraint,
+			Reason:     "constraint is empty",
+		}
+	}
+
+	if strings.EqualFold(strings.TrimSpace(constraint), VersionConstraintLatest) {
+		return semver.AnyConstraint, nil
+	}
+
+	offset := 0
+	for _, alternative := range strings.Split(constraint, "||") {
+		if strings.TrimSpace(alternative) == "" {
+			// Point at the closest || separator
+			column := offset - 1
+			if offset == 0 {
+				column = len(alternative) + 1
+			}
+
+			return semver.Constraint{}, VersionConstraintError{
+				Constraint: constraint,
+				Token:      "||",
+				Reason:     "empty alternative next to",
+				Column:     column,
+			}
+		}
+
+		if err := validateConstraintTokens(constraint, alternative, offset); err != nil {
+			return semver.Constraint{}, err
+		}
+
+		offset += len(alternative) + len("||")
+	}
+
+	parsed, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return semver.Constraint{}, VersionConstraintError{
+			Constraint: constraint,
+			Reason:     err.Error(),
+		}
+	}
+
+	if parsed.IsEmpty() {
+		return semver.Constraint{}, VersionConstraintError{
+			Constraint: constraint,
+			Reason:     "constraint does not match any version",
+		}
+	}
+
+	return parsed, nil
+}
+
+// ValidateVersionConstraint returns an error if the constraint can not be understood by the resolver.
+func ValidateVersionConstraint(constraint string) error {
+	_, err := ParseVersionConstraint(constraint)
+	return err
+}
+
+func validateConstraintTokens(constraint string, alternative string, offset int) error {
+	fields := strings.Fields(alternative)
+	position := 0
+
+	for i, field := range fields {
+		position += strings.Index(alternative[position:], field)
+		column := offset + position + 1
+		position += len(field)
+
+		if field == "-" {
+			if i == 0 || i == len(fields)-1 {
+				return VersionConstraintError{
+					Constraint: constraint,
+					Token:      field,
+					Reason:     "incomplete hyphen range",
+					Column:     column,
+				}
+			}
+			continue
+		}
+
+		if _, err := semver.NewConstraint(field); err != nil {
+			return VersionConstraintError{
+				Constraint: constraint,
+				Token:      field,
+				Reason:     "unexpected token",
+				Column:     column,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestParseVersionConstraint(t *testing.T) {
+	valid := []string{
+		"1.2.3",
+		">=1.2.3",
+		"^1.2.3",
+		"~1.2.3",
+		">=1.2.0 <2.0.0",
+		"1.2.3 - 2.0.0",
+		"^1.2.0 || ^2.0.0",
+		"1.2.x",
+		"*",
+		"latest",
+	}
+
+	for _, constraint := range valid {
+		_, err := ParseVersionConstraint(constraint)
+		testza.AssertNoError(t, err, constraint)
+	}
+
+	invalid := map[string]int{
+		">=1.2.0 foo":   9,
+		"^1.0.0 ||":     8,
+		"|| ^1.0.0":     1,
+		">=1.0.0 <=a.b": 9,
+		"1.0.0 -":       7,
+	}
+
+	for constraint, column := range invalid {
+		_, err := ParseVersionConstraint(constraint)
+
+		var constraintErr VersionConstraintError
+		testza.AssertTrue(t, errors.As(err, &constraintErr), constraint)
+		testza.AssertEqual(t, column, constraintErr.Column, constraint)
+	}
+
+	_, err := ParseVersionConstraint("")
+	testza.AssertNotNil(t, err)
+
+	testza.AssertEqual(t, "*", NormalizeVersionConstraint("latest"))
+	testza.AssertEqual(t, ">=1.0.0", NormalizeVersionConstraint(">=1.0.0"))
+}